@@ -2,154 +2,341 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"io"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
-	"triviaMultiplayer/internal/models"
+	"triviaMultiplayer/internal/idioma"
+	"triviaMultiplayer/internal/perguntas"
+	"triviaMultiplayer/internal/registro"
 	"triviaMultiplayer/internal/server"
 )
 
 // Constante para o número máximo de jogadores
 const maxJogadores = 10
 
-// Carrega perguntas do arquivo JSON
-func carregarPerguntasDoArquivo(caminho string, limite int) ([]models.Pergunta, error) {
-	//Lê o arquivo JSON
-	arquivoBytes, err := os.ReadFile(caminho)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao ler o arquivo: %w", err)
+// escolherPacotes lê a escolha do host: vazio usa todos os pacotes, ou números/nomes separados por vírgula
+func escolherPacotes(banco *perguntas.Banco, linha string) ([]string, error) {
+	linha = strings.TrimSpace(linha)
+	if linha == "" {
+		return nil, nil
 	}
 
-	// Decodifica o JSON para a struct
-	var perguntasJSON []models.PerguntaJSON
-	err = json.Unmarshal(arquivoBytes, &perguntasJSON)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao decodificar o JSON: %w", err)
+	disponiveis := banco.Pacotes()
+	var escolhidos []string
+	vistos := make(map[string]bool) // o mesmo pacote escolhido duas vezes entra uma vez só
+	for _, item := range strings.Split(linha, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		nome := item
+		if indice, err := strconv.Atoi(item); err == nil {
+			if indice < 1 || indice > len(disponiveis) {
+				return nil, fmt.Errorf("não existe pacote número %d", indice)
+			}
+			nome = disponiveis[indice-1]
+		} else if _, ok := banco.Pacote(item); !ok {
+			return nil, fmt.Errorf("pacote %q não encontrado", item)
+		}
+		if !vistos[nome] {
+			vistos[nome] = true
+			escolhidos = append(escolhidos, nome)
+		}
 	}
+	return escolhidos, nil
+}
 
-	// Embaralha as perguntas
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	r.Shuffle(len(perguntasJSON), func(i, j int) {
-		perguntasJSON[i], perguntasJSON[j] = perguntasJSON[j], perguntasJSON[i]
-	})
-
-	// Limita o número de perguntas
-	if limite > 0 && len(perguntasJSON) > limite {
-		perguntasJSON = perguntasJSON[:limite]
+// mostrarPacotes lista os pacotes disponíveis e os arquivos inválidos para o host
+func mostrarPacotes(banco *perguntas.Banco) {
+	fmt.Println(idioma.T("PacotesDePerguntas"))
+	for i, nome := range banco.Pacotes() {
+		pacote, _ := banco.Pacote(nome)
+		fmt.Println(idioma.T("PacoteListado", idioma.Dados{"Numero": i + 1, "Nome": nome, "Quantidade": len(pacote.Perguntas)}))
 	}
-
-	// Converte para o formato de Pergunta do jogo
-	var perguntasJogo []models.Pergunta
-	for i, pJSON := range perguntasJSON {
-		perguntasJogo = append(perguntasJogo, models.Pergunta{
-			Tipo:         "pergunta",
-			ID:           i + 1, // ID sequencial
-			Texto:        pJSON.Enunciado,
-			Opcoes:       pJSON.Alternativas,
-			OpcaoCorreta: pJSON.Resposta,
-		})
+	for arquivo, err := range banco.Erros() {
+		fmt.Println(idioma.T("PacoteInvalidoListado", idioma.Dados{"Arquivo": arquivo, "Erro": err}))
 	}
-
-	return perguntasJogo, nil
 }
 
-// Faz contagem regressiva inicial
-func contagemRegressiva(servidor *server.ServerJogo, valor int) {
-	for i := valor; i > 0; i-- {
-		servidor.TransmitirMsg([]byte(fmt.Sprintf("{\"tipo\":\"contagem_regressiva\",\"valor\":%d}\n", i)))
-		fmt.Printf("Começando em %d...\n", i)
-		time.Sleep(1 * time.Second)
+// nomePadrao usa o nome da máquina como nome do servidor
+func nomePadrao() string {
+	nome, err := os.Hostname()
+	if err != nil {
+		return "Trivia"
 	}
-	servidor.TransmitirMsg([]byte("{\"tipo\":\"contagem_regressiva\",\"valor\":0}\n"))
+	return nome
 }
 
-// Envia placar
-func enviarPlacar(servidor *server.ServerJogo, tipoMsg string) {
-	jogadores := servidor.RetornarJogadores()
-	var pontuacaoAtual []models.Pontuacao
+func main() {
+	dirPerguntas := flag.String("perguntas", "perguntas", "diretório com os pacotes de perguntas (.json)")
+	arquivoHistorico := flag.String("historico", "historico.json", "arquivo onde fica o histórico de perguntas já feitas")
+	cooldown := flag.Duration("cooldown", 24*time.Hour, "tempo até uma pergunta poder ser repetida para os mesmos jogadores")
+	embaralharPorJogador := flag.Bool("embaralhar-por-jogador", false, "cada jogador recebe as alternativas numa ordem diferente")
+	entradaTardia := flag.String("entrada-tardia", server.EntradaFila, "quem conecta durante a partida: fila (espera a próxima), zero ou menor (entra com zero ou com a menor pontuação)")
+	compensarLatencia := flag.Bool("compensar-latencia", false, "desconta metade do ping de cada jogador ao ordenar quem acertou primeiro")
+	empateMs := flag.Int("empate-ms", 0, "respostas corretas a até esse tempo (ms) da primeira do grupo empatam e dividem a posição")
+	idiomaServidor := flag.String("idioma", idioma.Padrao, "idioma das mensagens do console do servidor (pt-BR ou en-US)")
+	porta := flag.Int("porta", 8080, "porta TCP do jogo")
+	nomeServidor := flag.String("nome", nomePadrao(), "nome do servidor mostrado na procura da rede local")
+	nomeSala := flag.String("sala", "Principal", "nome da sala mostrado na procura da rede local")
+	enderecoHTTP := flag.String("http", ":8081", "endereço do cliente web e do WebSocket (vazio desativa)")
+	usarTLS := flag.Bool("tls", false, "aceita apenas conexões TLS")
+	arquivoCert := flag.String("tls-cert", "cert.pem", "certificado TLS (gerado autoassinado se não existir)")
+	arquivoChave := flag.String("tls-chave", "chave.pem", "chave privada do certificado TLS")
+	tokenAdmin := flag.String("admin-token", "", "token da API de administração em /admin/ (vazio sorteia um)")
+	logFormato := flag.String("log-formato", "texto", "formato dos registros: texto ou json")
+	logNivel := flag.String("log-nivel", "warn", "nível mínimo dos registros: debug, info, warn ou error")
+	logArquivo := flag.String("log-arquivo", "", "arquivo dos registros (vazio escreve no stderr)")
+	logTamanho := flag.Int64("log-tamanho-max", 10, "tamanho em MB para rodar o arquivo de registros")
+	logCopias := flag.Int("log-copias", 5, "arquivos de registros antigos mantidos")
+	tamanhoFila := flag.Int("fila-envio", server.TamanhoFilaPadrao, "mensagens que podem esperar na fila de cada jogador antes de ele ser desconectado")
+	prazoEscrita := flag.Duration("prazo-escrita", server.PrazoEscritaPadrao, "tempo máximo de uma escrita para um jogador antes de ele ser desconectado")
+	intervaloPing := flag.Duration("ping-intervalo", server.IntervaloPingPadrao, "intervalo entre os pings enviados a cada jogador")
+	limitePing := flag.Duration("ping-limite", server.LimitePingPadrao, "tempo extra sem nenhuma mensagem do jogador antes de ele ser desconectado")
+	reacaoMinima := flag.Duration("reacao-minima", server.ReacaoMinimaPadrao, "respostas mais rápidas que isso são registradas como suspeitas")
+	limiteMensagens := flag.Int("limite-mensagens", server.MensagensPorSegundoPadrao, "mensagens por segundo aceitas de cada jogador")
+	arquivoAuditoria := flag.String("auditoria-arquivo", "", "arquivo JSON das mensagens rejeitadas e respostas suspeitas (vazio usa o registro normal)")
+	modo := flag.String("modo", server.ModoClassico, "modo de jogo: classico, sobrevivencia, eliminacao, morte_subita, contra_relogio, rei_da_colina ou campainha")
+	vidas := flag.Int("vidas", 3, "vidas de cada jogador no modo eliminacao")
+	ajudas := flag.Int("ajudas", 1, "quantas vezes cada ajuda (meio_a_meio, mais_tempo, dobro, pular) pode ser usada por partida; 0 desliga")
+	rodadaFinal := flag.Bool("rodada-final", false, "termina a partida com uma pergunta em que cada jogador aposta parte dos pontos")
+	duracaoContraRelogio := flag.Int("duracao-contra-relogio", 120, "segundos de jogo no modo contra_relogio")
+	tempoRevelacao := flag.Int("tempo-revelacao", 5, "segundos mostrando a resposta certa depois de cada pergunta")
+	tempoPlacar := flag.Int("tempo-placar", 5, "segundos mostrando o placar parcial entre as perguntas")
+	tempoEncerramento := flag.Duration("encerramento", time.Minute, "tempo que a partida em andamento tem para terminar ao encerrar o servidor")
+	flag.Parse()
 
-	for _, jogador := range jogadores {
-		pontuacaoAtual = append(pontuacaoAtual, models.Pontuacao{
-			Jogador: jogador.Nome,
-			Pontos:  jogador.Pontuacao,
-		})
+	idioma.DefinirPadrao(*idiomaServidor)
+
+	logger, fecharLog, err := registro.Novo(registro.Config{
+		Formato:       *logFormato,
+		Nivel:         *logNivel,
+		Arquivo:       *logArquivo,
+		TamanhoMaximo: *logTamanho << 20,
+		Copias:        *logCopias,
+	})
+	if err != nil {
+		panic(err)
 	}
+	defer fecharLog.Close()
+	slog.SetDefault(logger)
 
-	// Usa o tipo de mensagem que foi passado como argumento.
-	placar := models.Placar{Tipo: tipoMsg, Pontuacoes: pontuacaoAtual}
-	placarBytes, _ := json.Marshal(placar)
-	servidor.TransmitirMsg(append(placarBytes, '\n'))
-}
+	banco, err := perguntas.NovoBanco(*dirPerguntas)
+	if err != nil {
+		panic(err)
+	}
+	historico, err := perguntas.NovoHistorico(*arquivoHistorico, *cooldown)
+	if err != nil {
+		panic(err)
+	}
+	banco.UsarHistorico(historico)
+	if err := banco.Observar(); err != nil {
+		fmt.Println(err)
+	}
+	defer banco.Parar()
 
-func executarJogo(servidor *server.ServerJogo, perguntas []models.Pergunta) {
-	// Contagem regressiva
-	contagemRegressiva(servidor, 3)
+	servidor := server.NovoServer(maxJogadores)
+	servidor.UsarLog(logger)
+	servidor.ConfigurarEnvio(*tamanhoFila, *prazoEscrita)
+	servidor.ConfigurarBatimentos(*intervaloPing, *limitePing)
+	servidor.ConfigurarValidacao(*reacaoMinima, *limiteMensagens)
+	if *arquivoAuditoria != "" {
+		auditoria, fecharAuditoria, err := registro.Novo(registro.Config{
+			Formato:       "json",
+			Nivel:         "info",
+			Arquivo:       *arquivoAuditoria,
+			TamanhoMaximo: *logTamanho << 20,
+			Copias:        *logCopias,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer fecharAuditoria.Close()
+		servidor.UsarAuditoria(auditoria)
+	}
+	servidor.DefinirSala(*nomeSala)
+	servidor.UsarBanco(banco)
+	config := servidor.Config()
+	config.EmbaralharPorJogador = *embaralharPorJogador
+	config.EntradaTardia = *entradaTardia
+	config.CompensarLatencia = *compensarLatencia
+	config.EmpateMs = *empateMs
+	config.TempoRevelacao = *tempoRevelacao
+	config.Modo = *modo
+	config.Vidas = *vidas
+	config.RodadaFinal = *rodadaFinal
+	config.Ajudas = *ajudas
+	config.DuracaoContraRelogio = *duracaoContraRelogio
+	config.TempoPlacar = *tempoPlacar
+	if err := servidor.DefinirConfig(config); err != nil {
+		panic(err)
+	}
+	if *usarTLS {
+		certificado, err := server.CarregarCertificado(*arquivoCert, *arquivoChave, true)
+		if err != nil {
+			panic(err)
+		}
+		servidor.UsarTLS(certificado)
+		fmt.Println(idioma.T("ImpressaoDigitalTLS", idioma.Dados{"Impressao": server.ImpressaoDigital(certificado)}))
+	}
+	err = servidor.IniciarServer(fmt.Sprintf(":%d", *porta)) //iniciando o servidor
+	if err != nil {
+		panic(err)
+	}
+	defer servidor.Parar()
 
-	// Executa cada pergunta
-	for i, pergunta := range perguntas {
-		perguntaBytes, _ := json.Marshal(pergunta)
-		servidor.TransmitirMsg(append(perguntaBytes, '\n'))
+	fmt.Println(idioma.T("ServidorOuvindo", idioma.Dados{"Endereco": fmt.Sprintf("%s:%d", server.ObterIPlocal(), *porta)}))
 
-		respostas := servidor.ColetarRespostas(10*time.Second, pergunta.OpcaoCorreta)
+	if err := servidor.IniciarDescoberta(*nomeServidor, *porta); err != nil {
+		fmt.Println(err)
+	}
 
-		pontos := server.CalcularPontos(respostas, pergunta.OpcaoCorreta)
-		for _, ponto := range pontos {
-			servidor.AtualizarPontos(ponto.Jogador, ponto.Pontos)
+	// O QR code leva ao cliente web quando ele está ativo; senão, ao endereço com o código
+	enderecoQR := fmt.Sprintf("trivia://%s:%d?codigo=%s", server.ObterIPlocal(), *porta, servidor.Codigo())
+	if *enderecoHTTP != "" {
+		if *tokenAdmin == "" {
+			*tokenAdmin = server.GerarTokenAdmin()
 		}
-
-		time.Sleep(5 * time.Second) // Pausa para a tela de Resultado da resposta
-
-		//Verifica se não é a última pergunta antes de enviar o placar parcial
-		ultimaPergunta := (i == len(perguntas)-1)
-		if !ultimaPergunta {
-			enviarPlacar(servidor, "placar")
-			time.Sleep(5 * time.Second) // Pausa apenas se não for a última pergunta
+		servidor.HabilitarAdmin(*tokenAdmin)
+		if err := servidor.IniciarHTTP(*enderecoHTTP); err != nil {
+			panic(err)
+		}
+		esquema := "http"
+		if *usarTLS {
+			esquema = "https"
 		}
+		_, portaHTTP, _ := net.SplitHostPort(*enderecoHTTP)
+		enderecoQR = esquema + "://" + server.ObterIPlocal() + ":" + portaHTTP
+		fmt.Println(idioma.T("ClienteWeb", idioma.Dados{"URL": enderecoQR}))
+		fmt.Println(idioma.T("PainelAdmin", idioma.Dados{"URL": enderecoQR + "/admin/", "Token": *tokenAdmin}))
 	}
 
-	fmt.Println("Fim de jogo!")
-	// Envia o placar final com o tipo "fim_de_jogo".
-	enviarPlacar(servidor, "fim_de_jogo")
-
-	time.Sleep(1 * time.Second)
-}
-
-func main() {
-	servidor := server.NovoServer(maxJogadores)
-	err := servidor.IniciarServer(":8080") //iniciando o servidor
-	if err != nil {
-		panic(err)
+	fmt.Println(idioma.T("CodigoSala", idioma.Dados{"Sala": servidor.Sala(), "Codigo": servidor.Codigo()}))
+	if qr, err := server.QRCode(enderecoQR); err == nil {
+		fmt.Print(qr)
 	}
-	defer servidor.Parar()
 
-	fmt.Printf("Servidor ouvindo em %s:8080\n", server.ObterIPlocal())
+	// SIGINT/SIGTERM encerram o servidor sem derrubar a partida em andamento
+	ctx, pararSinais := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer pararSinais()
+	linhas := lerLinhas(os.Stdin)
 
 	for {
 		fmt.Printf("\n----------------------------------\n")
-		fmt.Printf("Aguardando jogadores... (%d/%d)\n", servidor.RetornarNumJogadores(), maxJogadores)
-		fmt.Println("Pressione ENTER a qualquer momento para iniciar a partida com os jogadores conectados.")
+		fmt.Println(idioma.T("AguardandoJogadores", idioma.Dados{"Conectados": servidor.RetornarNumJogadores(), "Maximo": maxJogadores}))
+		fmt.Println(idioma.T("CodigoSala", idioma.Dados{"Sala": servidor.Sala(), "Codigo": servidor.Codigo()}))
+		mostrarPacotes(banco)
+		fmt.Println(idioma.T("PressioneEnter"))
+		fmt.Println(idioma.T("EscolhaDePacotes"))
 
-		bufio.NewReader(os.Stdin).ReadBytes('\n')
+		linha, ok := esperarLinha(ctx, linhas)
+		if !ok {
+			break
+		}
+		pacotes, err := escolherPacotes(banco, linha)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
 
-		numPlayers := servidor.RetornarNumJogadores()
-		if numPlayers == 0 {
-			fmt.Println("Nenhum jogador conectado. Aguardando novamente...")
+		// A partida também pode ser iniciada pelo painel de administração
+		err = servidor.IniciarPartida(pacotes)
+		switch {
+		case errors.Is(err, server.ErrSemJogadores):
+			fmt.Println(idioma.T("NenhumJogador"))
 			continue // Volta para o início do ciclo.
+		case errors.Is(err, server.ErrPartidaEmAndamento):
+			fmt.Println(idioma.T("PartidaEmAndamento"))
+		case err != nil:
+			fmt.Println(idioma.T("ErroCarregarPerguntas", idioma.Dados{"Erro": err}))
+			continue
+		}
+		fmt.Println(idioma.T("ComandosPartida"))
+		if acompanharPartida(ctx, servidor, linhas) != nil {
+			break
 		}
 
-		fmt.Printf("\nO jogo vai começar com %d jogador(es)!\n", numPlayers)
-		servidor.TransmitirMsg([]byte("{\"tipo\":\"inicio_jogo\"}\n"))
+		fmt.Println(idioma.T("PartidaFinalizada"))
+	}
 
-		perguntas, err := carregarPerguntasDoArquivo("perguntas.json", 5) //carrega 5 perguntas do perguntas.json
-		if err != nil {
-			fmt.Printf("Erro ao carregar perguntas: %v\n", err)
-			return
+	// Encerramento: a partida em andamento tem até -encerramento para terminar
+	pararSinais() // um segundo Ctrl+C volta a matar o processo na hora
+	fmt.Println()
+	fmt.Println(idioma.T("EncerrandoServidor", idioma.Dados{"Tempo": tempoEncerramento.String()}))
+	servidor.Encerrar(*tempoEncerramento)
+	if err := historico.Salvar(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(idioma.T("ServidorEncerrado"))
+}
+
+// lerLinhas lê o console numa goroutine, para o laço principal também poder esperar sinais.
+// O canal é fechado quando a entrada termina.
+func lerLinhas(entrada io.Reader) <-chan string {
+	linhas := make(chan string)
+	go func() {
+		defer close(linhas)
+		leitor := bufio.NewReader(entrada)
+		for {
+			linha, err := leitor.ReadString('\n')
+			if err != nil {
+				return
+			}
+			linhas <- linha
 		}
+	}()
+	return linhas
+}
+
+// acompanharPartida espera a partida terminar atendendo os comandos digitados no console
+func acompanharPartida(ctx context.Context, servidor *server.ServerJogo, linhas <-chan string) error {
+	fim := make(chan error, 1)
+	go func() { fim <- servidor.AguardarPartida(ctx) }()
 
-		executarJogo(servidor, perguntas)
+	for {
+		select {
+		case err := <-fim:
+			return err
+		case linha, ok := <-linhas:
+			if !ok {
+				return <-fim
+			}
+			switch strings.ToLower(strings.TrimSpace(linha)) {
+			case "pausar":
+				servidor.Pausar()
+			case "retomar":
+				servidor.Retomar()
+			case "pular":
+				servidor.Pular()
+			case "abortar":
+				servidor.Abortar()
+			}
+		}
+	}
+}
 
-		fmt.Println("Partida finalizada. O servidor está pronto para uma nova rodada.")
+// esperarLinha retorna a próxima linha do console, ou false quando chega um sinal de encerramento.
+// Sem console (entrada fechada), o servidor segue pelo painel de administração até o sinal.
+func esperarLinha(ctx context.Context, linhas <-chan string) (string, bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", false
+		case linha, ok := <-linhas:
+			if ok {
+				return linha, true
+			}
+			linhas = nil
+		}
 	}
 }
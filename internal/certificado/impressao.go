@@ -0,0 +1,19 @@
+// Impressão digital dos certificados TLS, a mesma no registro do servidor e na confirmação do cliente
+
+package certificado
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Impressao calcula o SHA-256 de um certificado em DER no formato AB:CD:...
+func Impressao(certDER []byte) string {
+	soma := sha256.Sum256(certDER)
+	partes := make([]string, len(soma))
+	for i, b := range soma {
+		partes[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(partes, ":")
+}
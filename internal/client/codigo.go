@@ -0,0 +1,60 @@
+// Entrada por código de sala: o código é procurado na rede local e vira o endereço do servidor
+
+package client
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TamanhoCodigo é o número de caracteres de um código de sala (o mesmo do servidor)
+const TamanhoCodigo = 6
+
+// ErrCodigoNaoEncontrado indica que nenhum servidor da rede local anunciou o código
+var ErrCodigoNaoEncontrado = errors.New("código de sala não encontrado na rede local")
+
+// InterpretarEndereco separa o que o jogador digitou em endereço e código de sala.
+// Aceita "IP:porta", um código (ex: K7M2QX) ou o conteúdo do QR code (trivia://IP:porta?codigo=K7M2QX).
+func InterpretarEndereco(texto string) (endereco, codigo string) {
+	texto = strings.TrimSpace(texto)
+	if strings.HasPrefix(texto, "trivia://") {
+		if u, err := url.Parse(texto); err == nil {
+			return u.Host, strings.ToUpper(u.Query().Get("codigo"))
+		}
+	}
+	if pareceCodigo(texto) {
+		return "", strings.ToUpper(texto)
+	}
+	return texto, ""
+}
+
+// pareceCodigo informa se o texto tem o formato de um código de sala e não de um endereço
+func pareceCodigo(texto string) bool {
+	if len(texto) != TamanhoCodigo {
+		return false
+	}
+	for _, c := range texto {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolverCodigo procura na rede local o servidor cuja sala tem o código pedido
+func ResolverCodigo(codigo string, tempo time.Duration) (ServidorEncontrado, error) {
+	servidores, err := ProcurarServidores(tempo)
+	if err != nil {
+		return ServidorEncontrado{}, err
+	}
+	for _, servidor := range servidores {
+		for _, sala := range servidor.Salas {
+			if strings.EqualFold(sala.Codigo, codigo) {
+				return servidor, nil
+			}
+		}
+	}
+	return ServidorEncontrado{}, ErrCodigoNaoEncontrado
+}
@@ -0,0 +1,163 @@
+// Conexões TLS e fixação de certificados na primeira conexão (trust-on-first-use)
+
+package client
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"triviaMultiplayer/internal/certificado"
+)
+
+// ModoConexao define como o cliente se conecta ao servidor
+type ModoConexao int
+
+const (
+	ConexaoSimples   ModoConexao = iota // TCP sem criptografia
+	ConexaoTLS                          // TLS verificando o certificado pelas autoridades do sistema
+	ConexaoTLSFixada                    // TLS fixando a impressão digital vista na primeira conexão
+)
+
+// ErrImpressaoDiferente indica que o servidor apresentou um certificado diferente do fixado
+type ErrImpressaoDiferente struct {
+	Endereco string
+	Esperada string
+	Recebida string
+}
+
+func (e *ErrImpressaoDiferente) Error() string {
+	return fmt.Sprintf("o certificado de %s mudou: esperava %s, recebeu %s", e.Endereco, e.Esperada, e.Recebida)
+}
+
+// ErrCertificadoRecusado indica que o jogador não confiou no certificado novo
+var ErrCertificadoRecusado = errors.New("certificado recusado")
+
+// ServidoresConhecidos guarda a impressão digital fixada de cada endereço
+type ServidoresConhecidos struct {
+	arquivo    string
+	impressoes map[string]string
+	mutex      *sync.Mutex
+}
+
+// ArquivoServidoresConhecidos retorna o caminho padrão, na pasta de configuração do usuário
+func ArquivoServidoresConhecidos() string {
+	pasta, err := os.UserConfigDir()
+	if err != nil {
+		pasta = "."
+	}
+	return filepath.Join(pasta, "trivia-multiplayer", "servidores_conhecidos.json")
+}
+
+// CarregarServidoresConhecidos lê as impressões fixadas, se o arquivo existir
+func CarregarServidoresConhecidos(arquivo string) (*ServidoresConhecidos, error) {
+	conhecidos := &ServidoresConhecidos{
+		arquivo:    arquivo,
+		impressoes: make(map[string]string),
+		mutex:      &sync.Mutex{},
+	}
+
+	arquivoBytes, err := os.ReadFile(arquivo)
+	if errors.Is(err, os.ErrNotExist) {
+		return conhecidos, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(arquivoBytes, &conhecidos.impressoes); err != nil {
+		return nil, err
+	}
+	return conhecidos, nil
+}
+
+// Impressao retorna a impressão fixada para o endereço
+func (conhecidos *ServidoresConhecidos) Impressao(endereco string) (string, bool) {
+	conhecidos.mutex.Lock()
+	defer conhecidos.mutex.Unlock()
+
+	impressao, ok := conhecidos.impressoes[endereco]
+	return impressao, ok
+}
+
+// Fixar salva a impressão digital do endereço
+func (conhecidos *ServidoresConhecidos) Fixar(endereco, impressao string) error {
+	conhecidos.mutex.Lock()
+	conhecidos.impressoes[endereco] = impressao
+	conhecidos.mutex.Unlock()
+	return conhecidos.salvar()
+}
+
+// Esquecer remove a impressão fixada, para aceitar um certificado novo
+func (conhecidos *ServidoresConhecidos) Esquecer(endereco string) error {
+	conhecidos.mutex.Lock()
+	delete(conhecidos.impressoes, endereco)
+	conhecidos.mutex.Unlock()
+	return conhecidos.salvar()
+}
+
+func (conhecidos *ServidoresConhecidos) salvar() error {
+	conhecidos.mutex.Lock()
+	arquivoBytes, err := json.MarshalIndent(conhecidos.impressoes, "", "  ")
+	conhecidos.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(conhecidos.arquivo), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(conhecidos.arquivo, arquivoBytes, 0600)
+}
+
+// NovaConexClienteTLS conecta com TLS. No modo fixado, o certificado não é verificado pelas
+// autoridades: na primeira conexão confirmar decide se a impressão digital deve ser fixada,
+// e nas seguintes ela precisa ser igual à salva.
+func NovaConexClienteTLS(endereco string, modo ModoConexao, conhecidos *ServidoresConhecidos, confirmar func(impressao string) bool) (*ConexCliente, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if modo == ConexaoTLSFixada {
+		config.InsecureSkipVerify = true // a verificação é feita pela impressão fixada, logo abaixo
+	} else if host, _, err := net.SplitHostPort(endereco); err == nil {
+		config.ServerName = host
+	}
+
+	conex, err := tls.Dial("tcp", endereco, config)
+	if err != nil {
+		return nil, err
+	}
+
+	certificados := conex.ConnectionState().PeerCertificates
+	if len(certificados) == 0 {
+		conex.Close()
+		return nil, errors.New("o servidor não apresentou certificado")
+	}
+	impressao := certificado.Impressao(certificados[0].Raw)
+
+	if modo == ConexaoTLSFixada {
+		esperada, conhecido := conhecidos.Impressao(endereco)
+		switch {
+		case conhecido && esperada != impressao:
+			conex.Close()
+			return nil, &ErrImpressaoDiferente{Endereco: endereco, Esperada: esperada, Recebida: impressao}
+		case !conhecido:
+			if !confirmar(impressao) {
+				conex.Close()
+				return nil, ErrCertificadoRecusado
+			}
+			if err := conhecidos.Fixar(endereco, impressao); err != nil {
+				conex.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return &ConexCliente{
+		Conex:     conex,
+		Leitor:    bufio.NewReader(conex),
+		Impressao: impressao,
+	}, nil
+}
@@ -10,8 +10,9 @@ import (
 
 // Encapsula a conexão e o writer/reader
 type ConexCliente struct {
-	Conex  net.Conn
-	Leitor *bufio.Reader
+	Conex     net.Conn
+	Leitor    *bufio.Reader
+	Impressao string // impressão digital do certificado, vazia sem TLS
 }
 
 // Cria uma nova conexão e retorna a struct
@@ -0,0 +1,104 @@
+// Procura de servidores na rede local por broadcast UDP
+
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// PortaDescoberta é a porta UDP onde os servidores escutam as procuras (a mesma do servidor)
+const PortaDescoberta = 8089
+
+// ServidorEncontrado é um servidor que respondeu à procura, com o endereço pronto para conectar
+type ServidorEncontrado struct {
+	models.Anuncio
+	Endereco string
+}
+
+// ProcurarServidores envia uma procura por broadcast e junta as respostas que chegarem dentro do tempo
+func ProcurarServidores(tempo time.Duration) ([]ServidorEncontrado, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	procura, _ := json.Marshal(models.Mensagem{Tipo: "procurar_servidores"})
+	for _, destino := range enderecosBroadcast() {
+		conn.WriteToUDP(procura, &net.UDPAddr{IP: destino, Port: PortaDescoberta})
+	}
+
+	encontrados := make(map[string]ServidorEncontrado)
+	conn.SetReadDeadline(time.Now().Add(tempo))
+	buffer := make([]byte, 4096)
+	for {
+		n, origem, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			break // fim do tempo de procura
+		}
+
+		var anuncio models.Anuncio
+		if json.Unmarshal(buffer[:n], &anuncio) != nil || anuncio.Tipo != "anuncio" {
+			continue
+		}
+		// O endereço vem do pacote, que é o IP pelo qual o servidor é alcançável daqui
+		endereco := net.JoinHostPort(origem.IP.String(), strconv.Itoa(anuncio.Porta))
+		encontrados[endereco] = ServidorEncontrado{Anuncio: anuncio, Endereco: endereco}
+	}
+
+	// Um servidor nesta máquina responde pelo loopback e pela rede; fica só a resposta da rede
+	servidores := make([]ServidorEncontrado, 0, len(encontrados))
+	for endereco, servidor := range encontrados {
+		if host, _, _ := net.SplitHostPort(endereco); net.ParseIP(host).IsLoopback() && temNaRede(encontrados, servidor) {
+			continue
+		}
+		servidores = append(servidores, servidor)
+	}
+	sort.Slice(servidores, func(i, j int) bool {
+		return servidores[i].Nome < servidores[j].Nome
+	})
+	return servidores, nil
+}
+
+// temNaRede informa se o mesmo servidor (nome e porta) também respondeu por um IP da rede
+func temNaRede(encontrados map[string]ServidorEncontrado, servidor ServidorEncontrado) bool {
+	for endereco, outro := range encontrados {
+		host, _, _ := net.SplitHostPort(endereco)
+		if !net.ParseIP(host).IsLoopback() && outro.Nome == servidor.Nome && outro.Porta == servidor.Porta {
+			return true
+		}
+	}
+	return false
+}
+
+// enderecosBroadcast retorna o broadcast geral e o de cada rede IPv4 da máquina
+func enderecosBroadcast() []net.IP {
+	destinos := []net.IP{net.IPv4bcast, net.IPv4(127, 0, 0, 1)}
+
+	enderecos, err := net.InterfaceAddrs()
+	if err != nil {
+		return destinos
+	}
+	for _, endereco := range enderecos {
+		ipnet, ok := endereco.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.To4() == nil {
+			continue
+		}
+		ip := ipnet.IP.To4()
+		mascara := net.IP(ipnet.Mask).To4()
+		if mascara == nil {
+			continue
+		}
+		broadcast := make(net.IP, 4)
+		for i := range ip {
+			broadcast[i] = ip[i] | ^mascara[i]
+		}
+		destinos = append(destinos, broadcast)
+	}
+	return destinos
+}
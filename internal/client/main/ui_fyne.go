@@ -3,12 +3,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image/color"
 	"strconv"
+	"strings"
 	"sync" // Importado para usar o sync.Once
 	"time"
 	"triviaMultiplayer/internal/client"
+	"triviaMultiplayer/internal/idioma"
 	"triviaMultiplayer/internal/models"
 
 	"fyne.io/fyne/v2"
@@ -16,6 +19,7 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -23,18 +27,92 @@ import (
 type AppUI struct {
 	janela  fyne.Window
 	conexao *client.ConexCliente
+	idioma  string
+	ip      string // guardados para não perder o que foi digitado ao trocar de idioma
+	nome    string
+	modo    client.ModoConexao
+
+	salaEspera *fyne.Container   // lista da sala de espera, atualizada a cada sala_espera
+	jogadores  models.SalaEspera // última lista recebida, mostrada ao voltar para a espera
+
+	encerrarPergunta func(models.PerguntaEncerrada) // fecha a pergunta na tela quando chega pergunta_encerrada
+	vezCampainha     func(models.Vez)               // libera ou trava as alternativas quando a vez muda no modo campainha
+	ajudaUsada       func(models.AjudaUsada)        // aplica na pergunta aberta o efeito da ajuda confirmada pelo servidor
+	pausa            *pausaPartida
+}
+
+// pausaPartida congela os cronômetros das telas enquanto o anfitrião pausa a partida
+type pausaPartida struct {
+	mutex    *sync.Mutex
+	retomar  chan struct{} // não nulo enquanto pausada; fechado na retomada
+	restante int           // segundos que faltam na etapa, informados pelo servidor na retomada
+	aviso    dialog.Dialog
+}
+
+// pausar mostra o aviso de pausa, que também impede responder, e para os cronômetros
+func (ui *AppUI) pausar(restante int) {
+	ui.pausa.mutex.Lock()
+	defer ui.pausa.mutex.Unlock()
+
+	if ui.pausa.retomar != nil {
+		return
+	}
+	ui.pausa.retomar = make(chan struct{})
+	texto := widget.NewLabel(ui.t("PausadaPeloAnfitriao", idioma.Dados{"Segundos": restante}))
+	ui.pausa.aviso = dialog.NewCustomWithoutButtons(ui.t("TituloPausa"), texto, ui.janela)
+	ui.pausa.aviso.Show()
+}
+
+// retomar fecha o aviso e solta os cronômetros com o tempo que o servidor diz faltar.
+// Também é chamada quando a partida acaba durante a pausa.
+func (ui *AppUI) retomar(restante int) {
+	ui.pausa.mutex.Lock()
+	defer ui.pausa.mutex.Unlock()
+
+	if ui.pausa.retomar == nil {
+		return
+	}
+	ui.pausa.restante = restante
+	close(ui.pausa.retomar)
+	ui.pausa.retomar = nil
+	ui.pausa.aviso.Hide()
+}
+
+// esperar bloqueia o cronômetro enquanto a partida está pausada. Se houve pausa, retorna
+// os segundos que faltam segundo o servidor.
+func (p *pausaPartida) esperar() (int, bool) {
+	p.mutex.Lock()
+	retomar := p.retomar
+	p.mutex.Unlock()
+
+	if retomar == nil {
+		return 0, false
+	}
+	<-retomar
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.restante, true
+}
+
+// t traduz uma mensagem para o idioma escolhido pelo jogador
+func (ui *AppUI) t(id string, dados ...idioma.Dados) string {
+	return idioma.Traduzir(ui.idioma, id, dados...)
 }
 
 // A função main é o ponto de entrada da nossa aplicação gráfica
 func main() {
 	a := app.New()
-	w := a.NewWindow("Trivia Multiplayer")
-	w.Resize(fyne.NewSize(400, 350))
 
 	ui := &AppUI{
-		janela: w,
+		idioma: idioma.Escolher(lang.SystemLocale().String()),
+		pausa:  &pausaPartida{mutex: &sync.Mutex{}},
 	}
 
+	w := a.NewWindow(ui.t("TituloJanela"))
+	w.Resize(fyne.NewSize(400, 350))
+	ui.janela = w
+
 	w.SetContent(telaInicial(ui))
 	w.ShowAndRun()
 }
@@ -50,65 +128,233 @@ func telaInicial(ui *AppUI) fyne.CanvasObject {
 	label2 := canvas.NewText("💡 ", color.Gray{})
 	label2.TextSize = 20
 
-	label3 := canvas.NewText("Digite o IP do servidor:", color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	label3 := canvas.NewText(ui.t("DigiteIP"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	entryIP := widget.NewEntry()
-	entryIP.SetPlaceHolder("Ex: 127.0.0.1:8080")
+	entryIP.SetPlaceHolder(ui.t("ExemploIP"))
+	entryIP.SetText(ui.ip)
 
-	label4 := canvas.NewText("Digite seu nome de jogador:", color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	label4 := canvas.NewText(ui.t("DigiteNome"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	entryNome := widget.NewEntry()
-	entryNome.SetPlaceHolder("Ex: Eistein")
+	entryNome.SetPlaceHolder(ui.t("ExemploNome"))
+	entryNome.SetText(ui.nome)
+
+	// Trocar o idioma redesenha a tela com os textos traduzidos
+	labelIdioma := canvas.NewText(ui.t("Idioma"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	selectIdioma := widget.NewSelect(idioma.Idiomas, nil)
+	selectIdioma.SetSelected(ui.idioma)
+	selectIdioma.OnChanged = func(valor string) {
+		ui.idioma = valor
+		ui.ip = entryIP.Text
+		ui.nome = entryNome.Text
+		ui.janela.SetTitle(ui.t("TituloJanela"))
+		ui.janela.SetContent(telaInicial(ui))
+	}
 
-	button := widget.NewButton("Entrar", func() {
-		ip := entryIP.Text
-		nomeJogador := entryNome.Text
+	// Modo de conexão: sem criptografia, TLS verificado ou TLS com impressão digital fixada
+	modos := []string{ui.t("ConexaoSimples"), ui.t("ConexaoTLS"), ui.t("ConexaoTLSFixada")}
+	selectModo := widget.NewSelect(modos, nil)
+	selectModo.SetSelectedIndex(int(ui.modo))
+	selectModo.OnChanged = func(string) {
+		ui.modo = client.ModoConexao(selectModo.SelectedIndex())
+	}
 
-		// Inicia a conexão numa goroutine
+	// Servidores encontrados na rede local; escolher um deles preenche o endereço
+	labelProcura := widget.NewLabel("")
+	listaServidores := container.NewVBox()
+	procurar := func() {
+		labelProcura.SetText(ui.t("ProcurandoServidores"))
 		go func() {
-			conex, err := client.NovaConexCliente(ip)
+			servidores, err := client.ProcurarServidores(time.Second)
 			if err != nil {
-				dialog.ShowError(err, ui.janela)
+				labelProcura.SetText(ui.t("ErroProcurarServidores", idioma.Dados{"Erro": err}))
 				return
 			}
-			ui.conexao = conex
-
-			err = ui.conexao.EnviarJSON(nomeJogador)
-			if err != nil {
-				dialog.ShowError(err, ui.janela)
-				return
+			listaServidores.RemoveAll()
+			for _, servidor := range servidores {
+				servidor := servidor
+				listaServidores.Add(widget.NewButton(descreverServidor(ui, servidor), func() {
+					entryIP.SetText(servidor.Endereco)
+					if servidor.TLS && ui.modo == client.ConexaoSimples {
+						selectModo.SetSelectedIndex(int(client.ConexaoTLSFixada))
+					}
+				}))
 			}
-
-			ui.janela.SetContent(telaAguardoInicial(ui)) //se a conexão der certo vai para a tela de espera inicial
-			go lerServidorEAtualizarUI(ui)               //inicia a escuta de todas as mensagens do servidor
+			labelProcura.SetText(ui.t("ServidoresEncontrados", idioma.Dados{"Quantidade": len(servidores)}))
 		}()
+	}
+	botaoProcurar := widget.NewButton(ui.t("ProcurarServidores"), procurar)
+	procurar()
+
+	button := widget.NewButton(ui.t("Entrar"), func() {
+		ui.ip = entryIP.Text
+		ui.nome = entryNome.Text
+
+		// Inicia a conexão numa goroutine
+		go conectar(ui)
 	})
 
 	return container.NewCenter(container.NewVBox(
 		(container.NewHBox(label0, label1, label2)),
+		container.NewHBox(labelIdioma, selectIdioma),
+		container.NewHBox(labelProcura, botaoProcurar),
+		listaServidores,
 		label3,
 		entryIP,
+		selectModo,
 		label4,
 		entryNome,
 		button,
 	))
 }
 
+// descreverServidor monta o texto de um servidor da rede local: nome, sala e lotação
+func descreverServidor(ui *AppUI, servidor client.ServidorEncontrado) string {
+	texto := servidor.Nome
+	for _, sala := range servidor.Salas {
+		texto += ui.t("DescricaoSala", idioma.Dados{"Sala": sala.Nome, "Codigo": sala.Codigo, "Jogadores": sala.Jogadores, "Maximo": sala.Maximo})
+	}
+	if servidor.TLS {
+		texto += " 🔒"
+	}
+	return texto
+}
+
+// conectar abre a conexão no modo escolhido, envia o nome e passa para a tela de espera
+func conectar(ui *AppUI) {
+	// Um código de sala é trocado pelo endereço do servidor que o anuncia na rede local
+	endereco, codigo := client.InterpretarEndereco(ui.ip)
+	modo := ui.modo
+	if codigo != "" && endereco == "" {
+		servidor, err := client.ResolverCodigo(codigo, 2*time.Second)
+		if errors.Is(err, client.ErrCodigoNaoEncontrado) {
+			dialog.ShowInformation(ui.t("TituloJanela"), ui.t("CodigoNaoEncontrado", idioma.Dados{"Codigo": codigo}), ui.janela)
+			return
+		}
+		if err != nil {
+			dialog.ShowError(err, ui.janela)
+			return
+		}
+		endereco = servidor.Endereco
+		if servidor.TLS && modo == client.ConexaoSimples {
+			modo = client.ConexaoTLSFixada
+		}
+	}
+
+	var conex *client.ConexCliente
+	var err error
+	if modo == client.ConexaoSimples {
+		conex, err = client.NovaConexCliente(endereco)
+	} else {
+		var conhecidos *client.ServidoresConhecidos
+		conhecidos, err = client.CarregarServidoresConhecidos(client.ArquivoServidoresConhecidos())
+		if err == nil {
+			conex, err = client.NovaConexClienteTLS(endereco, modo, conhecidos, func(impressao string) bool {
+				return confirmarCertificado(ui, endereco, impressao)
+			})
+		}
+
+		// O certificado mudou: mostra as duas impressões e deixa o jogador decidir se confia no novo
+		var diferente *client.ErrImpressaoDiferente
+		if errors.As(err, &diferente) {
+			mensagem := ui.t("CertificadoMudou", idioma.Dados{"Endereco": diferente.Endereco, "Esperada": diferente.Esperada, "Recebida": diferente.Recebida})
+			dialog.ShowConfirm(ui.t("TituloCertificadoMudou"), mensagem, func(confiar bool) {
+				if confiar && conhecidos.Esquecer(diferente.Endereco) == nil {
+					go conectar(ui)
+				}
+			}, ui.janela)
+			return
+		}
+	}
+	if errors.Is(err, client.ErrCertificadoRecusado) {
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, ui.janela)
+		return
+	}
+	ui.conexao = conex
+
+	err = ui.conexao.EnviarJSON(models.Entrar{Tipo: "entrar", Nome: ui.nome, Idioma: ui.idioma})
+	if err != nil {
+		dialog.ShowError(err, ui.janela)
+		return
+	}
+
+	ui.jogadores = models.SalaEspera{}
+	ui.janela.SetContent(telaAguardoInicial(ui)) //se a conexão der certo vai para a tela de espera inicial
+	go lerServidorEAtualizarUI(ui)               //inicia a escuta de todas as mensagens do servidor
+}
+
+// confirmarCertificado pergunta ao jogador se confia no certificado visto pela primeira vez.
+// Bloqueia a goroutine de conexão até o jogador responder.
+func confirmarCertificado(ui *AppUI, endereco, impressao string) bool {
+	resposta := make(chan bool)
+	mensagem := ui.t("ConfirmarCertificado", idioma.Dados{"Endereco": endereco, "Impressao": impressao})
+	dialog.ShowConfirm(ui.t("TituloCertificadoNovo"), mensagem, func(confiar bool) {
+		resposta <- confiar
+	}, ui.janela)
+	return <-resposta
+}
+
 func telaAguardoInicial(ui *AppUI) fyne.CanvasObject {
-	label1 := canvas.NewText("O jogo começará em breve", color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	label1 := canvas.NewText(ui.t("JogoComecaraEmBreve"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	label1.TextSize = 20
 	label1.TextStyle = fyne.TextStyle{Bold: true}
 
-	label2 := widget.NewLabel("Aguardando os outros jogadores...")
+	label2 := widget.NewLabel(ui.t("AguardandoOutros"))
 	barraProgresso := widget.NewProgressBarInfinite()
 
+	ui.salaEspera = container.NewVBox()
+	atualizarSalaEspera(ui, ui.jogadores)
+
 	return container.NewCenter(container.NewVBox(
 		label1,
 		label2,
 		barraProgresso,
+		widget.NewLabel(ui.t("JogadoresNaSala")),
+		ui.salaEspera,
 	))
 }
 
+// telaEntradaPartida mostra a quem entrou no meio da partida em que pergunta ela está e o placar
+func telaEntradaPartida(ui *AppUI, entrada models.EntradaPartida) fyne.CanvasObject {
+	titulo := canvas.NewText(ui.t("EntrouNoMeio", idioma.Dados{"Pergunta": entrada.Pergunta, "Total": entrada.Total}), color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	titulo.TextStyle = fyne.TextStyle{Bold: true}
+
+	conteudo := container.NewVBox(titulo)
+	for _, p := range entrada.Pontuacoes {
+		conteudo.Add(widget.NewLabel(fmt.Sprintf("%s: %s", p.Jogador, ui.t("Pontos", idioma.Dados{"Quantidade": p.Pontos}))))
+	}
+	return container.NewCenter(conteudo)
+}
+
+// atualizarSalaEspera mostra os jogadores conectados com um indicador da qualidade da conexão
+func atualizarSalaEspera(ui *AppUI, sala models.SalaEspera) {
+	ui.jogadores = sala
+	if ui.salaEspera == nil {
+		return
+	}
+	ui.salaEspera.RemoveAll()
+	for _, jogador := range sala.Jogadores {
+		var cor color.Color = color.Gray{Y: 160} // ainda sem medida
+		texto := jogador.Jogador
+		if jogador.Ping >= 0 {
+			texto = ui.t("PingJogador", idioma.Dados{"Nome": jogador.Jogador, "Ping": jogador.Ping})
+			switch {
+			case jogador.Ping < 100:
+				cor = color.NRGBA{R: 0, G: 180, B: 0, A: 255}
+			case jogador.Ping < 300:
+				cor = color.NRGBA{R: 230, G: 160, B: 0, A: 255}
+			default:
+				cor = color.NRGBA{R: 200, G: 0, B: 0, A: 255}
+			}
+		}
+		ui.salaEspera.Add(container.NewHBox(canvas.NewText("●", cor), widget.NewLabel(texto)))
+	}
+}
+
 func telaContagem(ui *AppUI, tempo int) fyne.CanvasObject {
-	texto := fmt.Sprintf("O jogo começa em:")
+	texto := ui.t("JogoComecaEm")
 	label := canvas.NewText(texto, color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	label.Alignment = fyne.TextAlignCenter
 	label.TextSize = 20
@@ -116,7 +362,7 @@ func telaContagem(ui *AppUI, tempo int) fyne.CanvasObject {
 
 	texto2 := fmt.Sprintf("%d", tempo)
 	if tempo <= 0 {
-		texto2 = "VAI!"
+		texto2 = ui.t("Vai")
 		time.Sleep(1 * time.Second)
 	}
 	labelCont := canvas.NewText(texto2, color.RGBA{R: 173, G: 216, B: 230, A: 255})
@@ -129,47 +375,195 @@ func telaContagem(ui *AppUI, tempo int) fyne.CanvasObject {
 
 func telaPerguntas(ui *AppUI, pergunta models.Pergunta) fyne.CanvasObject {
 	label1 := canvas.NewText(pergunta.Texto, color.Black)
+	if pergunta.Final {
+		label1.Text = ui.t("PerguntaFinal") + " " + pergunta.Texto
+	}
 
 	timerLabel := canvas.NewText("", color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	timerLabel.TextStyle = fyne.TextStyle{Bold: true}
 	timerLabel.Alignment = fyne.TextAlignCenter
 
-	var botoes []*widget.Button
+	var botoes, botoesAjuda []*widget.Button
 	var once sync.Once // Garante que a ação de resposta, clique ou tempoEsgotado, só acontece uma vez
+	respondeu, pulou := false, false
+	fim := make(chan struct{})     // fechado quando o servidor encerra a pergunta, para o cronômetro parar
+	prorrogar := make(chan int, 1) // segundos a mais da ajuda mais_tempo
+	desabilitar := func() {
+		for _, b := range append(botoes, botoesAjuda...) {
+			b.Disable()
+		}
+	}
 
 	// Ação a ser executada quando um botão de resposta é clicado ou o tempo esgota
 	acaoResposta := func(opcao string, tempoEsgotado bool) {
 		once.Do(func() {
-			for _, b := range botoes {
-				b.Disable()
+			desabilitar()
+			if pergunta.Espectador {
+				return // quem assiste não tem resultado
 			}
 			if tempoEsgotado {
-				timerLabel.Text = "Tempo esgotado!"
+				timerLabel.Text = ui.t("TempoEsgotado")
 				// Se o tempo esgotou, o jogador não enviou resposta, então mostramos "Incorreta"
-				ui.janela.SetContent(telaResultadoResposta(ui, false))
+				ui.janela.SetContent(telaResultadoResposta(ui, models.ResultadoResposta{Correta: false}))
 			} else {
+				respondeu = true
 				enviarResposta(ui, pergunta.ID, opcao)
 			}
 		})
 	}
 
-	buttonA := widget.NewButton(pergunta.Opcoes[0], func() { acaoResposta("A", false) })
-	buttonB := widget.NewButton(pergunta.Opcoes[1], func() { acaoResposta("B", false) })
-	buttonC := widget.NewButton(pergunta.Opcoes[2], func() { acaoResposta("C", false) })
-	buttonD := widget.NewButton(pergunta.Opcoes[3], func() { acaoResposta("D", false) })
+	// O servidor encerra a pergunta quando todos respondem, o tempo acaba ou o anfitrião pula
+	var encerrada sync.Once
+	ui.encerrarPergunta = func(evento models.PerguntaEncerrada) {
+		if evento.ID != pergunta.ID {
+			return
+		}
+		encerrada.Do(func() {
+			close(fim)
+			once.Do(desabilitar)
+			if pergunta.Espectador || pulou {
+				timerLabel.Text = ui.t("RespostaCerta", idioma.Dados{"Letra": evento.RespostaCorreta, "Texto": evento.TextoCorreto})
+				timerLabel.Refresh()
+				return
+			}
+			if !respondeu {
+				// Quem não respondeu também fica sabendo qual era a certa
+				ui.janela.SetContent(telaResultadoResposta(ui, models.ResultadoResposta{
+					Correta:         false,
+					RespostaCorreta: evento.RespostaCorreta,
+					TextoCorreto:    evento.TextoCorreto,
+				}))
+			}
+		})
+	}
+
+	// As alternativas chegam sem letra, na ordem sorteada pelo servidor para este jogador
+	buttonA := widget.NewButton("A) "+pergunta.Opcoes[0], func() { acaoResposta("A", false) })
+	buttonB := widget.NewButton("B) "+pergunta.Opcoes[1], func() { acaoResposta("B", false) })
+	buttonC := widget.NewButton("C) "+pergunta.Opcoes[2], func() { acaoResposta("C", false) })
+	buttonD := widget.NewButton("D) "+pergunta.Opcoes[3], func() { acaoResposta("D", false) })
 
 	botoes = []*widget.Button{buttonA, buttonB, buttonC, buttonD}
+	if pergunta.Espectador {
+		// Eliminado: acompanha a pergunta sem poder responder
+		for _, b := range botoes {
+			b.Disable()
+		}
+		label1.Text = ui.t("Assistindo") + " " + pergunta.Texto
+	}
 
+	// Modo campainha: as alternativas só abrem quando o servidor dá a vez a este jogador
+	situacao := widget.NewLabel("")
+	botaoCampainha := widget.NewButton(ui.t("TocarCampainha"), nil)
+	botaoCampainha.Importance = widget.HighImportance
+	botaoCampainha.OnTapped = func() {
+		botaoCampainha.Disable()
+		situacao.SetText(ui.t("CampainhaTocada"))
+		if err := ui.conexao.EnviarJSON(models.Campainha{Tipo: "campainha", ID: pergunta.ID}); err != nil {
+			dialog.ShowError(err, ui.janela)
+		}
+	}
+	ui.vezCampainha = nil
+	if pergunta.Campainha && !pergunta.Espectador {
+		for _, b := range botoes {
+			b.Disable()
+		}
+		ui.vezCampainha = func(vez models.Vez) {
+			if vez.ID != pergunta.ID || respondeu {
+				return
+			}
+			if vez.Jogador == strings.TrimSpace(ui.nome) {
+				botaoCampainha.Disable()
+				situacao.SetText(ui.t("SuaVez", idioma.Dados{"Segundos": vez.Tempo}))
+				for _, b := range botoes {
+					b.Enable()
+				}
+				return
+			}
+			situacao.SetText(ui.t("VezDe", idioma.Dados{"Nome": vez.Jogador}))
+			for _, b := range botoes {
+				b.Disable()
+			}
+		}
+	}
+
+	// Ajudas: cada botão mostra o saldo, e o efeito só vale quando o servidor confirma
+	ajudas := []string{"meio_a_meio", "mais_tempo", "dobro", "pular"}
+	rotulosAjuda := map[string]string{"meio_a_meio": "AjudaMeioAMeio", "mais_tempo": "AjudaMaisTempo", "dobro": "AjudaDobro", "pular": "AjudaPular"}
+	botaoAjuda := make(map[string]*widget.Button)
+	for _, ajuda := range ajudas {
+		ajuda := ajuda
+		botao := widget.NewButton(ui.t(rotulosAjuda[ajuda], idioma.Dados{"Restantes": pergunta.Ajudas[ajuda]}), func() {
+			botaoAjuda[ajuda].Disable()
+			if err := ui.conexao.EnviarJSON(models.Ajuda{Tipo: "ajuda", ID: pergunta.ID, Ajuda: ajuda}); err != nil {
+				dialog.ShowError(err, ui.janela)
+			}
+		})
+		if pergunta.Ajudas[ajuda] <= 0 {
+			botao.Disable()
+		}
+		botaoAjuda[ajuda] = botao
+		botoesAjuda = append(botoesAjuda, botao)
+	}
+	ui.ajudaUsada = func(usada models.AjudaUsada) {
+		if usada.ID != pergunta.ID || respondeu {
+			return
+		}
+		if botao, ok := botaoAjuda[usada.Ajuda]; ok {
+			botao.SetText(ui.t(rotulosAjuda[usada.Ajuda], idioma.Dados{"Restantes": usada.Restantes}))
+		}
+		switch usada.Ajuda {
+		case "meio_a_meio":
+			for _, letra := range usada.Remover {
+				if len(letra) == 1 && letra[0] >= 'A' && int(letra[0]-'A') < len(botoes) {
+					botoes[letra[0]-'A'].Disable()
+				}
+			}
+		case "mais_tempo":
+			select {
+			case prorrogar <- usada.Tempo:
+			default:
+			}
+		case "dobro":
+			situacao.SetText(ui.t("PontosEmDobro"))
+		case "pular":
+			once.Do(func() {
+				pulou = true
+				desabilitar()
+				situacao.SetText(ui.t("PerguntaPulada"))
+			})
+		}
+	}
+
+	tempo := pergunta.Tempo
+	if tempo == 0 {
+		tempo = 10 // servidores antigos não mandam o tempo
+	}
 	go func(tempoRestante int) {
 		for i := tempoRestante; i >= 0; i-- {
-			timerLabel.Text = "Tempo restante: " + strconv.Itoa(i) //strconv converte o i (int) em string
-			time.Sleep(1 * time.Second)                            //garante duração de tempo correta da função
+			timerLabel.Text = ui.t("TempoRestante", idioma.Dados{"Segundos": strconv.Itoa(i)}) //strconv converte o i (int) em string
+			select {
+			case <-fim:
+				return // a pergunta já foi encerrada pelo servidor
+			case extra := <-prorrogar:
+				i += extra + 1 // o segundo atual recomeça com o tempo extra
+				continue
+			case <-time.After(1 * time.Second): //garante duração de tempo correta da função
+			}
+			if restante, pausou := ui.pausa.esperar(); pausou {
+				i = restante + 1 // continua de onde o servidor parou
+			}
+		}
+		if pergunta.Campainha {
+			// Acabou o tempo para tocar; quem já está com a vez responde até o servidor encerrar
+			botaoCampainha.Disable()
+			return
 		}
 		// Quando o ciclo termina, o tempo esgotou
 		acaoResposta("", true)
-	}(10) //o parâmetro de tempo da goroutine é 10 segundos
+	}(tempo) //o parâmetro de tempo da goroutine é o tempo de resposta da pergunta
 
-	return container.NewVBox(
+	conteudo := container.NewVBox(
 		label1,
 		widget.NewSeparator(),
 		timerLabel,
@@ -179,18 +573,103 @@ func telaPerguntas(ui *AppUI, pergunta models.Pergunta) fyne.CanvasObject {
 		buttonC,
 		buttonD,
 	)
+	if pergunta.Campainha && !pergunta.Espectador {
+		// Botão grande para tocar a campainha rápido
+		conteudo.Add(widget.NewSeparator())
+		conteudo.Add(situacao)
+		conteudo.Add(container.NewGridWrap(fyne.NewSize(360, 120), botaoCampainha))
+	} else if len(pergunta.Ajudas) > 0 {
+		conteudo.Add(widget.NewSeparator())
+		linha := container.NewGridWithColumns(len(botoesAjuda))
+		for _, botao := range botoesAjuda {
+			linha.Add(botao)
+		}
+		conteudo.Add(linha)
+		conteudo.Add(situacao)
+	}
+	return conteudo
+}
+
+// telaAposta pede a aposta secreta da rodada final, de zero até os pontos do jogador
+func telaAposta(ui *AppUI, pedido models.PedidoAposta) fyne.CanvasObject {
+	titulo := canvas.NewText(ui.t("TituloRodadaFinal"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	titulo.TextStyle = fyne.TextStyle{Bold: true}
+	titulo.TextSize = 24
+	titulo.Alignment = fyne.TextAlignCenter
+
+	timerLabel := canvas.NewText("", color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	timerLabel.Alignment = fyne.TextAlignCenter
+
+	entrada := widget.NewEntry()
+	entrada.SetPlaceHolder("0")
+	var botaoApostar, botaoTudo *widget.Button
+	fim := make(chan struct{})
+	var enviou sync.Once
+	apostar := func(valor int) {
+		if valor < 0 || valor > pedido.Maximo {
+			dialog.ShowError(errors.New(ui.t("ApostaInvalida", idioma.Dados{"Maximo": pedido.Maximo})), ui.janela)
+			return
+		}
+		enviou.Do(func() {
+			close(fim)
+			entrada.Disable()
+			botaoApostar.Disable()
+			botaoTudo.Disable()
+			if err := ui.conexao.EnviarJSON(models.Aposta{Tipo: "aposta", Valor: valor}); err != nil {
+				dialog.ShowError(err, ui.janela)
+			}
+		})
+	}
+	botaoApostar = widget.NewButton(ui.t("Apostar"), func() {
+		valor, err := strconv.Atoi(strings.TrimSpace(entrada.Text))
+		if err != nil {
+			valor = -1
+		}
+		apostar(valor)
+	})
+	botaoApostar.Importance = widget.HighImportance
+	botaoTudo = widget.NewButton(ui.t("ApostarTudo"), func() { apostar(pedido.Maximo) })
+
+	go func(tempoRestante int) {
+		for i := tempoRestante; i >= 0; i-- {
+			timerLabel.Text = ui.t("TempoRestante", idioma.Dados{"Segundos": strconv.Itoa(i)})
+			timerLabel.Refresh()
+			select {
+			case <-fim:
+				return
+			case <-time.After(1 * time.Second):
+			}
+			if restante, pausou := ui.pausa.esperar(); pausou {
+				i = restante + 1
+			}
+		}
+		// Sem aposta, o servidor considera zero
+		enviou.Do(func() {
+			entrada.Disable()
+			botaoApostar.Disable()
+			botaoTudo.Disable()
+		})
+	}(pedido.Tempo)
+
+	return container.NewCenter(container.NewVBox(
+		titulo,
+		widget.NewLabel(ui.t("ApostePontos", idioma.Dados{"Maximo": pedido.Maximo})),
+		timerLabel,
+		entrada,
+		container.NewGridWithColumns(2, botaoApostar, botaoTudo),
+	))
 }
 
 // Mostra o resultado da resposta do jogador
-func telaResultadoResposta(ui *AppUI, correta bool) fyne.CanvasObject {
+func telaResultadoResposta(ui *AppUI, resultado models.ResultadoResposta) fyne.CanvasObject {
 	var textoResultado string
 	var corTexto color.Color
 
-	textoResultado = "Resposta Correta!"
+	textoResultado = ui.t("RespostaCorreta")
 	corTexto = color.NRGBA{R: 0, G: 180, B: 0, A: 255}
 
-	if !correta {
-		textoResultado = "Resposta Incorreta!"
+	if !resultado.Correta {
+		textoResultado = ui.t("RespostaIncorreta")
 		corTexto = color.NRGBA{R: 200, G: 0, B: 0, A: 255}
 	}
 
@@ -199,31 +678,44 @@ func telaResultadoResposta(ui *AppUI, correta bool) fyne.CanvasObject {
 	labelResultado.TextStyle = fyne.TextStyle{Bold: true}
 	labelResultado.Alignment = fyne.TextAlignCenter
 
-	label2 := widget.NewLabel("Esperando os outros jogadores responderem...")
+	conteudo := container.NewVBox(labelResultado)
+
+	// Revela a alternativa correta, com a letra na ordem em que o jogador a viu
+	if !resultado.Correta && resultado.RespostaCorreta != "" {
+		labelCorreta := widget.NewLabel(ui.t("RespostaCerta", idioma.Dados{"Letra": resultado.RespostaCorreta, "Texto": resultado.TextoCorreto}))
+		labelCorreta.Alignment = fyne.TextAlignCenter
+		conteudo.Add(labelCorreta)
+	}
+
+	label2 := widget.NewLabel(ui.t("EsperandoRespostas"))
 	label2.Alignment = fyne.TextAlignCenter
+	conteudo.Add(label2)
 
-	return container.NewCenter(container.NewVBox(
-		labelResultado,
-		label2))
+	return container.NewCenter(conteudo)
 }
 
 func telaPlacar(ui *AppUI, placar models.Placar, fim_de_jogo bool) fyne.CanvasObject {
-	titulo := canvas.NewText("Placar Parcial", color.RGBA{R: 0, G: 119, B: 190, A: 255})
+	titulo := canvas.NewText(ui.t("PlacarParcial"), color.RGBA{R: 0, G: 119, B: 190, A: 255})
 	titulo.Alignment = fyne.TextAlignCenter
 	titulo.TextStyle.Bold = true
 	titulo.TextSize = 30
 
 	if fim_de_jogo {
-		titulo.Text = "Placar Final"
+		titulo.Text = ui.t("PlacarFinal")
 	}
 
 	listaDePontos := container.NewVBox()
 	listaDePontos.Add(titulo)
 
 	for _, p := range placar.Pontuacoes {
-		nomeJogador := canvas.NewText(fmt.Sprintf("%s: ", p.Jogador), color.NRGBA{R: 128, G: 0, B: 128, A: 255})     // roxo
-		pontosJogador := canvas.NewText(fmt.Sprintf("%d pontos", p.Pontos), color.NRGBA{R: 0, G: 200, B: 0, A: 255}) // Verde
+		nomeJogador := canvas.NewText(fmt.Sprintf("%s: ", p.Jogador), color.NRGBA{R: 128, G: 0, B: 128, A: 255})                       // roxo
+		pontosJogador := canvas.NewText(ui.t("Pontos", idioma.Dados{"Quantidade": p.Pontos}), color.NRGBA{R: 0, G: 200, B: 0, A: 255}) // Verde
 		linhaDePonto := container.NewHBox(nomeJogador, pontosJogador)
+		if p.Eliminado > 0 {
+			linhaDePonto.Add(canvas.NewText(ui.t("EliminadoOrdem", idioma.Dados{"Ordem": p.Eliminado}), color.NRGBA{R: 200, G: 0, B: 0, A: 255}))
+		} else if p.Vidas > 0 {
+			linhaDePonto.Add(canvas.NewText(strings.Repeat("♥", p.Vidas), color.NRGBA{R: 200, G: 0, B: 0, A: 255}))
+		}
 		listaDePontos.Add(linhaDePonto)
 	}
 
@@ -231,30 +723,32 @@ func telaPlacar(ui *AppUI, placar models.Placar, fim_de_jogo bool) fyne.CanvasOb
 		barraProgresso := widget.NewProgressBar()
 		go func() {
 			duracaoTotal := 5.0
+			if placar.Tempo > 0 {
+				duracaoTotal = float64(placar.Tempo)
+			}
 			for i := 0.0; i <= duracaoTotal; i += 0.1 {
 
 				valor := i / duracaoTotal
 				barraProgresso.SetValue(valor)
 				time.Sleep(100 * time.Millisecond)
+				ui.pausa.esperar()
 			}
 		}()
 
 		conteudoInferior := container.NewVBox(
-			widget.NewLabel("Carregando próxima pergunta..."),
+			widget.NewLabel(ui.t("CarregandoProxima")),
 			barraProgresso,
 		)
 
 		return container.NewBorder(nil, conteudoInferior, nil, nil, listaDePontos)
 
 	} else {
-		botaoJogarDenovo := widget.NewButton("Jogar Novamente", func() {
+		botaoJogarDenovo := widget.NewButton(ui.t("JogarNovamente"), func() {
+			// A goroutine que ouve o servidor continua rodando e responde aos pings
 			ui.janela.SetContent(telaAguardoInicial(ui))
-
-			// Reiniciamos a goroutine que ouve o servidor para a nova partida.
-			go lerServidorEAtualizarUI(ui)
 		})
 
-		botaoSair := widget.NewButton("Sair do Jogo", func() {
+		botaoSair := widget.NewButton(ui.t("SairDoJogo"), func() {
 			// Fecha a aplicação do cliente
 			ui.janela.Close()
 		})
@@ -286,7 +780,8 @@ func lerServidorEAtualizarUI(ui *AppUI) {
 		var rawMsg map[string]interface{}
 		err := ui.conexao.ReceberJSON(&rawMsg) //recebe a msg de forma genérica em um map
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("Ligação perdida: %w", err), ui.janela)
+			ui.retomar(0)
+			dialog.ShowError(errors.New(ui.t("LigacaoPerdida", idioma.Dados{"Erro": err})), ui.janela)
 			ui.janela.SetContent(telaInicial(ui))
 			return
 		}
@@ -300,13 +795,42 @@ func lerServidorEAtualizarUI(ui *AppUI) {
 			ui.janela.SetContent(telaPerguntas(ui, pergunta))
 
 		case "resultado_resposta":
-			var data struct {
-				Correta bool `json:"correta"`
-			}
+			var resultado models.ResultadoResposta
 			bytes, _ := json.Marshal(rawMsg)
-			_ = json.Unmarshal(bytes, &data)
-			ui.janela.SetContent(telaResultadoResposta(ui, data.Correta))
+			_ = json.Unmarshal(bytes, &resultado)
+			ui.janela.SetContent(telaResultadoResposta(ui, resultado))
 
+		case "pergunta_encerrada":
+			var encerrada models.PerguntaEncerrada
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &encerrada)
+			if ui.encerrarPergunta != nil {
+				ui.encerrarPergunta(encerrada)
+			}
+		case "ajuda_usada":
+			var usada models.AjudaUsada
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &usada)
+			if ui.ajudaUsada != nil {
+				ui.ajudaUsada(usada)
+			}
+		case "aposta_pedido":
+			var pedido models.PedidoAposta
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &pedido)
+			ui.janela.SetContent(telaAposta(ui, pedido))
+		case "aposta_aceita":
+			var aposta models.Aposta
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &aposta)
+			ui.janela.SetContent(container.NewCenter(widget.NewLabel(ui.t("ApostaAceita", idioma.Dados{"Valor": aposta.Valor}))))
+		case "vez":
+			var vez models.Vez
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &vez)
+			if ui.vezCampainha != nil {
+				ui.vezCampainha(vez)
+			}
 		case "placar":
 			var placar models.Placar
 			bytes, _ := json.Marshal(rawMsg)
@@ -319,11 +843,68 @@ func lerServidorEAtualizarUI(ui *AppUI) {
 			bytes, _ := json.Marshal(rawMsg)
 			_ = json.Unmarshal(bytes, &data)
 			ui.janela.SetContent(telaContagem(ui, data.Valor))
+		case "eliminado":
+			// Continua recebendo a revelação e o placar até o fim da partida
+			dialog.ShowInformation(ui.t("TituloJanela"), ui.t("VoceFoiEliminado"), ui.janela)
+		case "pausa", "retomada":
+			var pausa models.Pausa
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &pausa)
+			if tipo == "pausa" {
+				ui.pausar(pausa.Restante)
+			} else {
+				ui.retomar(pausa.Restante)
+			}
 		case "fim_de_jogo":
+			ui.retomar(0) // a partida pode ter sido abortada durante a pausa
 			var placar models.Placar
 			bytes, _ := json.Marshal(rawMsg)
 			_ = json.Unmarshal(bytes, &placar)
 			ui.janela.SetContent(telaPlacar(ui, placar, true))
+		case "aguardando_partida":
+			var entrada models.EntradaPartida
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &entrada)
+			ui.janela.SetContent(telaAguardoInicial(ui))
+			dialog.ShowInformation(ui.t("TituloJanela"), ui.t("AguardandoProximaPartida", idioma.Dados{"Pergunta": entrada.Pergunta, "Total": entrada.Total}), ui.janela)
+		case "entrada_partida":
+			var entrada models.EntradaPartida
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &entrada)
+			ui.janela.SetContent(telaEntradaPartida(ui, entrada))
+		case "ping":
+			// Sem o pong o servidor considera a conexão morta
+			var ping models.Ping
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &ping)
+			ui.conexao.EnviarJSON(models.Ping{Tipo: "pong", ID: ping.ID})
+		case "sala_espera":
+			var sala models.SalaEspera
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &sala)
+			atualizarSalaEspera(ui, sala)
+		case "servidor_encerrando":
+			var placar models.Placar
+			bytes, _ := json.Marshal(rawMsg)
+			_ = json.Unmarshal(bytes, &placar)
+			ui.retomar(0)
+			ui.conexao.Fechar()
+			if len(placar.Pontuacoes) > 0 {
+				ui.janela.SetContent(telaPlacar(ui, placar, true))
+			} else {
+				ui.janela.SetContent(telaInicial(ui))
+			}
+			dialog.ShowInformation(ui.t("TituloJanela"), ui.t("ServidorEncerrando"), ui.janela)
+			return
+		case "expulso", "banido":
+			mensagem := ui.t("Expulso")
+			if tipo == "banido" {
+				mensagem = ui.t("Banido")
+			}
+			ui.retomar(0)
+			ui.conexao.Fechar()
+			dialog.ShowInformation(ui.t("TituloJanela"), mensagem, ui.janela)
+			ui.janela.SetContent(telaInicial(ui))
 			return
 		}
 	}
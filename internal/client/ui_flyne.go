@@ -1,3 +1,3 @@
 // componentes da interface do usuário flyne
 
-package client
\ No newline at end of file
+package client
@@ -0,0 +1,116 @@
+// Catálogos de mensagens do cliente e do servidor (pt-BR e en-US)
+
+package idioma
+
+import (
+	"embed"
+	"encoding/json"
+	"sync"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+// Padrao é o idioma usado quando o jogador não escolhe um idioma suportado
+const Padrao = "pt-BR"
+
+// Idiomas lista os idiomas com catálogo, na ordem mostrada ao jogador
+var Idiomas = []string{"pt-BR", "en-US"}
+
+//go:embed catalogos/*.json
+var catalogos embed.FS
+
+// Dados são os valores usados nos modelos das mensagens, como {{.Nome}}
+type Dados map[string]interface{}
+
+var (
+	bundle       *i18n.Bundle
+	localizers   = make(map[string]*i18n.Localizer)
+	idiomaPadrao = Padrao
+	mutex        = &sync.Mutex{}
+)
+
+func init() {
+	bundle = i18n.NewBundle(language.MustParse(Padrao))
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+	for _, tag := range Idiomas {
+		bundle.MustParseMessageFileBytes(mustLer("catalogos/"+tag+".json"), tag+".json")
+	}
+}
+
+func mustLer(caminho string) []byte {
+	conteudo, err := catalogos.ReadFile(caminho)
+	if err != nil {
+		panic(err)
+	}
+	return conteudo
+}
+
+// Catalogo retorna o arquivo de mensagens do idioma, usado pelo cliente web
+func Catalogo(tag string) ([]byte, bool) {
+	conteudo, err := catalogos.ReadFile("catalogos/" + tag + ".json")
+	return conteudo, err == nil
+}
+
+// Escolher retorna o idioma suportado mais próximo do pedido (ex: "en" vira "en-US")
+func Escolher(pedido string) string {
+	tags := make([]language.Tag, len(Idiomas))
+	for i, tag := range Idiomas {
+		tags[i] = language.MustParse(tag)
+	}
+	pedidoTag, err := language.Parse(pedido)
+	if err != nil {
+		return Padrao
+	}
+	_, indice, confianca := language.NewMatcher(tags).Match(pedidoTag)
+	if confianca == language.No {
+		return Padrao
+	}
+	return Idiomas[indice]
+}
+
+// DefinirPadrao troca o idioma usado por T, como o das mensagens do console do servidor
+func DefinirPadrao(tag string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	idiomaPadrao = Escolher(tag)
+}
+
+// T traduz a mensagem para o idioma padrão
+func T(id string, dados ...Dados) string {
+	mutex.Lock()
+	tag := idiomaPadrao
+	mutex.Unlock()
+	return Traduzir(tag, id, dados...)
+}
+
+// Traduzir traduz a mensagem para o idioma pedido. Se a mensagem não existir, retorna o próprio id.
+// O valor "Quantidade" nos dados escolhe a forma de plural.
+func Traduzir(tag, id string, dados ...Dados) string {
+	config := &i18n.LocalizeConfig{MessageID: id}
+	if len(dados) > 0 {
+		config.TemplateData = dados[0]
+		if quantidade, ok := dados[0]["Quantidade"]; ok {
+			config.PluralCount = quantidade
+		}
+	}
+
+	texto, err := localizer(tag).Localize(config)
+	if err != nil {
+		return id
+	}
+	return texto
+}
+
+// localizer guarda um Localizer por idioma para não recriá-lo a cada mensagem
+func localizer(tag string) *i18n.Localizer {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	l, ok := localizers[tag]
+	if !ok {
+		l = i18n.NewLocalizer(bundle, tag, Padrao)
+		localizers[tag] = l
+	}
+	return l
+}
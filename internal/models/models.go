@@ -2,41 +2,84 @@ package models
 
 import "time"
 
-// PerguntaJSON representa a estrutura das perguntas no arquivo JSON
+// PerguntaJSON representa a estrutura das perguntas no arquivo JSON.
+// As alternativas ficam sem letra; a resposta correta é a letra na ordem do arquivo.
 type PerguntaJSON struct {
+	Enunciado    string                      `json:"enunciado"`
+	Alternativas []string                    `json:"alternativas"`
+	Resposta     string                      `json:"resposta_correta"`
+	Traducoes    map[string]TraducaoPergunta `json:"traducoes,omitempty"`
+}
+
+// TraducaoPergunta traz o enunciado e as alternativas em outro idioma, na mesma ordem do original
+type TraducaoPergunta struct {
 	Enunciado    string   `json:"enunciado"`
 	Alternativas []string `json:"alternativas"`
-	Resposta     string   `json:"resposta_correta"`
 }
 
-// Pergunta representa uma pergunta do jogo
+// Pergunta representa uma pergunta do jogo.
+// Opcoes fica na ordem canônica no servidor e é embaralhada antes do envio.
 type Pergunta struct {
-	Tipo         string   `json:"tipo"`
-	ID           int      `json:"id"`
-	Texto        string   `json:"texto"`
-	Opcoes       []string `json:"opcoes"`
-	OpcaoCorreta string   `json:"-"`
+	Tipo       string                      `json:"tipo"`
+	ID         int                         `json:"id"`
+	Texto      string                      `json:"texto"`
+	Opcoes     []string                    `json:"opcoes"`
+	Tempo      int                         `json:"tempo,omitempty"`      // segundos para responder
+	Espectador bool                        `json:"espectador,omitempty"` // o jogador foi eliminado e só assiste
+	Campainha  bool                        `json:"campainha,omitempty"`  // só responde quem tocar a campainha e receber a vez
+	Final      bool                        `json:"final,omitempty"`      // pergunta da rodada final, valendo a aposta
+	Ajudas     map[string]int              `json:"ajudas,omitempty"`     // saldo de cada ajuda do jogador na partida
+	Correta    int                         `json:"-"`                    // índice canônico da alternativa correta
+	Traducoes  map[string]TraducaoPergunta `json:"-"`
+}
+
+// Traduzida retorna a pergunta no idioma pedido, ou a original se não houver tradução
+func (pergunta Pergunta) Traduzida(idioma string) Pergunta {
+	traducao, ok := pergunta.Traducoes[idioma]
+	if !ok {
+		return pergunta
+	}
+	pergunta.Texto = traducao.Enunciado
+	pergunta.Opcoes = traducao.Alternativas
+	return pergunta
 }
 
 // Resposta representa a resposta de um jogador
 type Resposta struct {
-	Tipo    string    `json:"tipo"`
-	ID      int       `json:"id"`
-	Jogador string    `json:"jogador"`
-	Opcao   string    `json:"opcao"`
-	Tempo   time.Time `json:"tempo"`
+	Tipo        string        `json:"tipo"`
+	ID          int           `json:"id"`
+	Jogador     string        `json:"jogador"`
+	JogadorID   int           `json:"-"` // identifica quem respondeu, mesmo com nomes repetidos
+	Opcao       string        `json:"opcao"`
+	Alternativa int           `json:"-"`     // índice canônico escolhido, -1 se a letra for inválida
+	Correta     bool          `json:"-"`     // avaliada uma vez, vale para o feedback e para os pontos
+	Tempo       time.Time     `json:"tempo"` // quando o servidor leu a resposta
+	RTT         time.Duration `json:"-"`     // tempo de ida e volta do jogador quando respondeu
+	Dobro       bool          `json:"-"`     // usou a ajuda dobro nesta pergunta
+	Pulou       bool          `json:"-"`     // usou a ajuda pular: sem alternativa, sem pontos e sem perder vida
+}
+
+// ResultadoResposta é o feedback imediato, com a letra correta na ordem vista pelo jogador
+type ResultadoResposta struct {
+	Tipo            string `json:"tipo"`
+	Correta         bool   `json:"correta"`
+	RespostaCorreta string `json:"resposta_correta"`
+	TextoCorreto    string `json:"texto_correto"`
 }
 
 // Pontuacao representa a pontuação de um jogador
 type Pontuacao struct {
-	Jogador string `json:"jogador"`
-	Pontos  int    `json:"pontos"`
+	Jogador   string `json:"jogador"`
+	Pontos    int    `json:"pontos"`
+	Vidas     int    `json:"vidas,omitempty"`     // vidas que restam, nos modos com vidas
+	Eliminado int    `json:"eliminado,omitempty"` // ordem de eliminação: 1 foi o primeiro a sair
 }
 
 // Placar representa o placar do jogo
 type Placar struct {
 	Tipo       string      `json:"tipo"`
 	Pontuacoes []Pontuacao `json:"pontuacoes"`
+	Tempo      int         `json:"tempo,omitempty"` // segundos até a próxima pergunta
 }
 
 // Mensagem genérica para identificar tipos de mensagens
@@ -64,3 +107,116 @@ type ServidorLotado struct {
 type NomeRequisicao struct {
 	Tipo string `json:"tipo"`
 }
+
+// Anuncio é a resposta do servidor a uma procura de servidores na rede local
+type Anuncio struct {
+	Tipo      string        `json:"tipo"`
+	Nome      string        `json:"nome"`
+	Porta     int           `json:"porta"`
+	TLS       bool          `json:"tls"`
+	Jogadores int           `json:"jogadores"`
+	Salas     []SalaAnuncio `json:"salas"`
+}
+
+// SalaAnuncio descreve uma sala no anúncio do servidor
+type SalaAnuncio struct {
+	Nome      string `json:"nome"`
+	Codigo    string `json:"codigo"`
+	Jogadores int    `json:"jogadores"`
+	Maximo    int    `json:"maximo"`
+}
+
+// Entrar é a resposta do cliente à solicitação de nome, com o idioma escolhido
+type Entrar struct {
+	Tipo   string `json:"tipo"`
+	Nome   string `json:"nome"`
+	Idioma string `json:"idioma"`
+}
+
+// Ping é o batimento do servidor; o cliente responde com um pong de mesmo ID
+type Ping struct {
+	Tipo string `json:"tipo"`
+	ID   int    `json:"id"`
+}
+
+// SalaEspera lista os jogadores conectados enquanto a partida não começa
+type SalaEspera struct {
+	Tipo      string             `json:"tipo"`
+	Jogadores []JogadorConectado `json:"jogadores"`
+}
+
+// JogadorConectado traz o tempo de ida e volta do jogador, -1 enquanto não foi medido
+type JogadorConectado struct {
+	Jogador string `json:"jogador"`
+	Ping    int    `json:"ping_ms"`
+}
+
+// EntradaPartida avisa quem conectou no meio da partida: aguardando_partida (joga a próxima)
+// ou entrada_partida, com o placar atual
+type EntradaPartida struct {
+	Tipo       string      `json:"tipo"`
+	Pergunta   int         `json:"pergunta"`
+	Total      int         `json:"total"`
+	Pontuacoes []Pontuacao `json:"pontuacoes,omitempty"`
+}
+
+// PerguntaEncerrada avisa que a pergunta fechou (todos responderam, o tempo acabou ou o anfitrião
+// pulou), com a resposta certa na ordem vista pelo jogador
+type PerguntaEncerrada struct {
+	Tipo            string `json:"tipo"`
+	ID              int    `json:"id"`
+	RespostaCorreta string `json:"resposta_correta"`
+	TextoCorreto    string `json:"texto_correto"`
+	Respostas       int    `json:"respostas"` // quantos jogadores responderam
+	Tempo           int    `json:"tempo"`     // segundos até o placar
+}
+
+// Pausa congela (pausa) ou solta (retomada) os cronômetros dos jogadores
+type Pausa struct {
+	Tipo     string `json:"tipo"`
+	Restante int    `json:"restante"` // segundos que faltam na etapa atual
+}
+
+// Campainha é o toque do jogador pedindo a vez de responder a pergunta aberta
+type Campainha struct {
+	Tipo string `json:"tipo"`
+	ID   int    `json:"id"`
+}
+
+// Vez avisa a todos quem pode responder agora no modo campainha, e por quantos segundos
+type Vez struct {
+	Tipo    string `json:"tipo"`
+	ID      int    `json:"id"`
+	Jogador string `json:"jogador"`
+	Tempo   int    `json:"tempo"`
+}
+
+// PedidoAposta abre as apostas da rodada final: cada jogador pode apostar de zero até Maximo
+type PedidoAposta struct {
+	Tipo   string `json:"tipo"`
+	Maximo int    `json:"maximo"`
+	Tempo  int    `json:"tempo"` // segundos para apostar
+}
+
+// Aposta é o valor apostado pelo jogador (aposta) ou a confirmação do servidor (aposta_aceita)
+type Aposta struct {
+	Tipo  string `json:"tipo"`
+	Valor int    `json:"valor"`
+}
+
+// Ajuda é o pedido do jogador para usar uma ajuda na pergunta aberta
+type Ajuda struct {
+	Tipo  string `json:"tipo"`
+	ID    int    `json:"id"`
+	Ajuda string `json:"ajuda"` // meio_a_meio, mais_tempo, dobro ou pular
+}
+
+// AjudaUsada confirma a ajuda, com o saldo que sobrou e o efeito na tela do jogador
+type AjudaUsada struct {
+	Tipo      string   `json:"tipo"`
+	ID        int      `json:"id"`
+	Ajuda     string   `json:"ajuda"`
+	Restantes int      `json:"restantes"`
+	Remover   []string `json:"remover,omitempty"` // letras tiradas pelo meio_a_meio
+	Tempo     int      `json:"tempo,omitempty"`   // segundos a mais do mais_tempo
+}
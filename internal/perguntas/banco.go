@@ -0,0 +1,413 @@
+// Banco de perguntas: carrega pacotes de um diretório e os recarrega quando mudam
+
+package perguntas
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"triviaMultiplayer/internal/idioma"
+	"triviaMultiplayer/internal/models"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Tempo de espera para agrupar vários eventos do mesmo salvamento
+const atrasoRecarga = 300 * time.Millisecond
+
+// Pacote representa um arquivo de perguntas do diretório
+type Pacote struct {
+	Nome      string
+	Descricao string
+	Arquivo   string
+	Perguntas []models.PerguntaJSON
+}
+
+// arquivoPacote é o formato com metadados aceito além da lista simples de perguntas
+type arquivoPacote struct {
+	Nome      string                `json:"nome"`
+	Descricao string                `json:"descricao"`
+	Perguntas []models.PerguntaJSON `json:"perguntas"`
+}
+
+// Banco guarda os pacotes carregados e os pacotes inválidos com o seu erro
+type Banco struct {
+	diretorio string
+	pacotes   map[string]*Pacote
+	erros     map[string]error
+	mutex     *sync.RWMutex
+	watcher   *fsnotify.Watcher
+	historico *Historico
+}
+
+// NovoBanco cria o banco e faz a primeira leitura do diretório
+func NovoBanco(diretorio string) (*Banco, error) {
+	info, err := os.Stat(diretorio)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o diretório de perguntas: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s não é um diretório", diretorio)
+	}
+
+	banco := &Banco{
+		diretorio: diretorio,
+		pacotes:   make(map[string]*Pacote),
+		erros:     make(map[string]error),
+		mutex:     &sync.RWMutex{},
+	}
+	banco.Recarregar()
+	return banco, nil
+}
+
+// Recarregar lê novamente todos os arquivos .json do diretório
+func (banco *Banco) Recarregar() {
+	pacotes := make(map[string]*Pacote)
+	erros := make(map[string]error)
+
+	arquivos, err := filepath.Glob(filepath.Join(banco.diretorio, "*.json"))
+	if err != nil {
+		erros[banco.diretorio] = err
+	}
+
+	for _, arquivo := range arquivos {
+		pacote, err := lerPacote(arquivo)
+		if err != nil {
+			erros[filepath.Base(arquivo)] = err
+			continue
+		}
+		if _, existe := pacotes[pacote.Nome]; existe {
+			erros[filepath.Base(arquivo)] = fmt.Errorf("já existe um pacote chamado %q", pacote.Nome)
+			continue
+		}
+		pacotes[pacote.Nome] = pacote
+	}
+
+	banco.mutex.Lock()
+	banco.pacotes = pacotes
+	banco.erros = erros
+	banco.mutex.Unlock()
+
+	for arquivo, err := range erros {
+		fmt.Println(idioma.T("PacoteInvalido", idioma.Dados{"Arquivo": arquivo, "Erro": err}))
+	}
+}
+
+// lerPacote lê e valida um arquivo de perguntas
+func lerPacote(arquivo string) (*Pacote, error) {
+	arquivoBytes, err := os.ReadFile(arquivo)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo: %w", err)
+	}
+	return decodificarPacote(arquivo, arquivoBytes)
+}
+
+// decodificarPacote decodifica e valida o conteúdo de um arquivo de perguntas
+func decodificarPacote(arquivo string, arquivoBytes []byte) (*Pacote, error) {
+	pacote := &Pacote{
+		Nome:    strings.TrimSuffix(filepath.Base(arquivo), filepath.Ext(arquivo)),
+		Arquivo: arquivo,
+	}
+
+	// Aceita tanto a lista simples quanto o objeto com nome e descrição
+	var conteudo arquivoPacote
+	if err := json.Unmarshal(arquivoBytes, &pacote.Perguntas); err != nil {
+		if err := json.Unmarshal(arquivoBytes, &conteudo); err != nil {
+			return nil, fmt.Errorf("erro ao decodificar o JSON: %w", err)
+		}
+		if conteudo.Nome != "" {
+			pacote.Nome = conteudo.Nome
+		}
+		pacote.Descricao = conteudo.Descricao
+		pacote.Perguntas = conteudo.Perguntas
+	}
+
+	if len(pacote.Perguntas) == 0 {
+		return nil, fmt.Errorf("o pacote não tem perguntas")
+	}
+	for i, pergunta := range pacote.Perguntas {
+		if err := validarPergunta(pergunta); err != nil {
+			return nil, fmt.Errorf("pergunta %d: %w", i+1, err)
+		}
+	}
+	return pacote, nil
+}
+
+// validarPergunta verifica se a pergunta pode ser usada numa partida
+func validarPergunta(pergunta models.PerguntaJSON) error {
+	if strings.TrimSpace(pergunta.Enunciado) == "" {
+		return fmt.Errorf("enunciado vazio")
+	}
+	if len(pergunta.Alternativas) != 4 {
+		return fmt.Errorf("esperava 4 alternativas, encontrou %d", len(pergunta.Alternativas))
+	}
+	if i := alternativaVazia(pergunta.Alternativas); i >= 0 {
+		return fmt.Errorf("alternativa %c vazia", 'A'+i)
+	}
+	resposta := strings.ToUpper(strings.TrimSpace(pergunta.Resposta))
+	if len(resposta) != 1 || resposta[0] < 'A' || resposta[0] > 'D' {
+		return fmt.Errorf("resposta_correta inválida: %q", pergunta.Resposta)
+	}
+	for tag, traducao := range pergunta.Traducoes {
+		if strings.TrimSpace(traducao.Enunciado) == "" {
+			return fmt.Errorf("tradução %s: enunciado vazio", tag)
+		}
+		if len(traducao.Alternativas) != len(pergunta.Alternativas) {
+			return fmt.Errorf("tradução %s: esperava %d alternativas, encontrou %d", tag, len(pergunta.Alternativas), len(traducao.Alternativas))
+		}
+		if i := alternativaVazia(traducao.Alternativas); i >= 0 {
+			return fmt.Errorf("tradução %s: alternativa %c vazia", tag, 'A'+i)
+		}
+	}
+	return nil
+}
+
+// alternativaVazia retorna a primeira alternativa sem texto (nem depois de tirar a letra), ou -1
+func alternativaVazia(alternativas []string) int {
+	for i, alternativa := range alternativas {
+		if strings.TrimSpace(removerLetra(alternativa)) == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// removerLetras aplica removerLetra a todas as alternativas
+func removerLetras(alternativas []string) []string {
+	opcoes := make([]string, len(alternativas))
+	for i, alternativa := range alternativas {
+		opcoes[i] = removerLetra(alternativa)
+	}
+	return opcoes
+}
+
+// removerLetra tira o prefixo "A) " dos pacotes antigos, que traziam a letra no texto
+func removerLetra(alternativa string) string {
+	alternativa = strings.TrimSpace(alternativa)
+	if len(alternativa) >= 2 && alternativa[1] == ')' && strings.ContainsRune("ABCDabcd", rune(alternativa[0])) {
+		return strings.TrimSpace(alternativa[2:])
+	}
+	return alternativa
+}
+
+// Adicionar valida o conteúdo de um pacote e o grava no diretório com o nome do arquivo informado.
+// Um arquivo com o mesmo nome é substituído. O banco é recarregado em seguida.
+func (banco *Banco) Adicionar(nomeArquivo string, conteudo []byte) (*Pacote, error) {
+	nomeArquivo = filepath.Base(strings.TrimSpace(nomeArquivo))
+	if filepath.Ext(nomeArquivo) != ".json" {
+		nomeArquivo += ".json"
+	}
+	if strings.HasPrefix(nomeArquivo, ".") {
+		return nil, fmt.Errorf("nome de arquivo inválido: %q", nomeArquivo)
+	}
+
+	arquivo := filepath.Join(banco.diretorio, nomeArquivo)
+	pacote, err := decodificarPacote(arquivo, conteudo)
+	if err != nil {
+		return nil, err
+	}
+	if existente, ok := banco.Pacote(pacote.Nome); ok && existente.Arquivo != arquivo {
+		return nil, fmt.Errorf("já existe um pacote chamado %q em %s", pacote.Nome, filepath.Base(existente.Arquivo))
+	}
+
+	// Grava num temporário e renomeia, para o observador não ler o arquivo pela metade
+	temporario := arquivo + ".tmp"
+	if err := os.WriteFile(temporario, conteudo, 0644); err != nil {
+		return nil, fmt.Errorf("erro ao gravar o pacote: %w", err)
+	}
+	if err := os.Rename(temporario, arquivo); err != nil {
+		os.Remove(temporario)
+		return nil, fmt.Errorf("erro ao gravar o pacote: %w", err)
+	}
+
+	banco.Recarregar()
+	return pacote, nil
+}
+
+// Observar inicia uma goroutine que recarrega o banco quando o diretório muda
+func (banco *Banco) Observar() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("erro ao observar o diretório de perguntas: %w", err)
+	}
+	if err := watcher.Add(banco.diretorio); err != nil {
+		watcher.Close()
+		return fmt.Errorf("erro ao observar o diretório de perguntas: %w", err)
+	}
+	banco.watcher = watcher
+
+	go func() {
+		var recarga <-chan time.Time
+		for {
+			select {
+			case evento, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(evento.Name) == ".json" {
+					recarga = time.After(atrasoRecarga)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Println(idioma.T("ErroObservarPerguntas", idioma.Dados{"Erro": err}))
+			case <-recarga:
+				recarga = nil
+				banco.Recarregar()
+				fmt.Println(idioma.T("PacotesRecarregados", idioma.Dados{"Pacotes": strings.Join(banco.Pacotes(), ", ")}))
+			}
+		}
+	}()
+	return nil
+}
+
+// Parar encerra a observação do diretório
+func (banco *Banco) Parar() {
+	if banco.watcher != nil {
+		banco.watcher.Close()
+	}
+}
+
+// Pacotes retorna os nomes dos pacotes válidos em ordem alfabética
+func (banco *Banco) Pacotes() []string {
+	banco.mutex.RLock()
+	defer banco.mutex.RUnlock()
+
+	nomes := make([]string, 0, len(banco.pacotes))
+	for nome := range banco.pacotes {
+		nomes = append(nomes, nome)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// Pacote retorna um pacote pelo nome
+func (banco *Banco) Pacote(nome string) (*Pacote, bool) {
+	banco.mutex.RLock()
+	defer banco.mutex.RUnlock()
+
+	pacote, ok := banco.pacotes[nome]
+	return pacote, ok
+}
+
+// Erros retorna uma cópia dos erros da última leitura, por arquivo
+func (banco *Banco) Erros() map[string]error {
+	banco.mutex.RLock()
+	defer banco.mutex.RUnlock()
+
+	erros := make(map[string]error, len(banco.erros))
+	for arquivo, err := range banco.erros {
+		erros[arquivo] = err
+	}
+	return erros
+}
+
+// UsarHistorico faz o sorteio evitar perguntas vistas recentemente pelos jogadores
+func (banco *Banco) UsarHistorico(historico *Historico) {
+	banco.historico = historico
+}
+
+// Sortear embaralha as perguntas dos pacotes escolhidos e seleciona até o limite.
+// Sem pacotes escolhidos, usa todos os pacotes válidos. Com histórico, as perguntas
+// que os jogadores ainda não viram vêm primeiro; se faltarem, completa com as vistas há mais tempo.
+// O sorteio não marca nada como visto: isso fica para RegistrarVista, quando a pergunta sai.
+func (banco *Banco) Sortear(nomesPacotes []string, limite int, jogadores []string) ([]models.Pergunta, error) {
+	if len(nomesPacotes) == 0 {
+		nomesPacotes = banco.Pacotes()
+	}
+
+	var perguntasJSON []models.PerguntaJSON
+	vistos := make(map[string]bool)
+	for _, nome := range nomesPacotes {
+		if vistos[nome] {
+			continue // pacote repetido não aumenta as chances das suas perguntas
+		}
+		vistos[nome] = true
+		pacote, ok := banco.Pacote(nome)
+		if !ok {
+			return nil, fmt.Errorf("pacote de perguntas %q não encontrado", nome)
+		}
+		perguntasJSON = append(perguntasJSON, pacote.Perguntas...)
+	}
+	if len(perguntasJSON) == 0 {
+		return nil, fmt.Errorf("nenhuma pergunta disponível")
+	}
+
+	// Embaralha as perguntas
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r.Shuffle(len(perguntasJSON), func(i, j int) {
+		perguntasJSON[i], perguntasJSON[j] = perguntasJSON[j], perguntasJSON[i]
+	})
+
+	if banco.historico != nil {
+		perguntasJSON = banco.priorizarNaoVistas(perguntasJSON, jogadores)
+	}
+
+	// Limita o número de perguntas
+	if limite > 0 && len(perguntasJSON) > limite {
+		perguntasJSON = perguntasJSON[:limite]
+	}
+
+	// Converte para o formato de Pergunta do jogo
+	var perguntasJogo []models.Pergunta
+	for i, pJSON := range perguntasJSON {
+		traducoes := make(map[string]models.TraducaoPergunta, len(pJSON.Traducoes))
+		for tag, traducao := range pJSON.Traducoes {
+			traducoes[tag] = models.TraducaoPergunta{
+				Enunciado:    traducao.Enunciado,
+				Alternativas: removerLetras(traducao.Alternativas),
+			}
+		}
+		perguntasJogo = append(perguntasJogo, models.Pergunta{
+			Tipo:      "pergunta",
+			ID:        i + 1, // ID sequencial
+			Texto:     pJSON.Enunciado,
+			Opcoes:    removerLetras(pJSON.Alternativas),
+			Correta:   int(strings.ToUpper(strings.TrimSpace(pJSON.Resposta))[0] - 'A'),
+			Traducoes: traducoes,
+		})
+	}
+
+	return perguntasJogo, nil
+}
+
+// RegistrarVista marca na memória a pergunta como vista pelos jogadores, no momento em que ela
+// é enviada. Sem histórico, não faz nada.
+func (banco *Banco) RegistrarVista(pergunta models.Pergunta, jogadores []string) {
+	if banco.historico != nil {
+		banco.historico.Registrar([]models.PerguntaJSON{{Enunciado: pergunta.Texto}}, jogadores)
+	}
+}
+
+// SalvarHistorico grava o histórico em disco, se houver um
+func (banco *Banco) SalvarHistorico() error {
+	if banco.historico == nil {
+		return nil
+	}
+	return banco.historico.Salvar()
+}
+
+// priorizarNaoVistas coloca as perguntas fora do cooldown primeiro, mantendo o embaralhamento,
+// e depois as recentes da mais antiga para a mais nova
+func (banco *Banco) priorizarNaoVistas(perguntasJSON []models.PerguntaJSON, jogadores []string) []models.PerguntaJSON {
+	var novas, recentes []models.PerguntaJSON
+	for _, pergunta := range perguntasJSON {
+		if banco.historico.Recente(pergunta, jogadores) {
+			recentes = append(recentes, pergunta)
+		} else {
+			novas = append(novas, pergunta)
+		}
+	}
+
+	sort.SliceStable(recentes, func(i, j int) bool {
+		return banco.historico.UltimaVez(recentes[i], jogadores).Before(banco.historico.UltimaVez(recentes[j], jogadores))
+	})
+	return append(novas, recentes...)
+}
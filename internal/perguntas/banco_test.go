@@ -0,0 +1,240 @@
+package perguntas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// perguntaValida monta uma pergunta que passa na validação, para os casos mudarem um campo só
+func perguntaValida() models.PerguntaJSON {
+	return models.PerguntaJSON{
+		Enunciado:    "Qual é o planeta mais próximo do Sol?",
+		Alternativas: []string{"Vênus", "Terra", "Mercúrio", "Marte"},
+		Resposta:     "C",
+		Traducoes: map[string]models.TraducaoPergunta{
+			"en-US": {Enunciado: "Which planet is closest to the Sun?", Alternativas: []string{"Venus", "Earth", "Mercury", "Mars"}},
+		},
+	}
+}
+
+func TestValidarPergunta(t *testing.T) {
+	casos := []struct {
+		nome   string
+		mudar  func(*models.PerguntaJSON)
+		valida bool
+	}{
+		{"válida", func(p *models.PerguntaJSON) {}, true},
+		{"letras dos pacotes antigos", func(p *models.PerguntaJSON) {
+			p.Alternativas = []string{"A) Vênus", "B) Terra", "C) Mercúrio", "D) Marte"}
+		}, true},
+		{"resposta minúscula", func(p *models.PerguntaJSON) { p.Resposta = " c " }, true},
+		{"enunciado vazio", func(p *models.PerguntaJSON) { p.Enunciado = "  " }, false},
+		{"três alternativas", func(p *models.PerguntaJSON) { p.Alternativas = p.Alternativas[:3] }, false},
+		{"alternativa vazia", func(p *models.PerguntaJSON) { p.Alternativas[1] = "" }, false},
+		{"alternativa em branco", func(p *models.PerguntaJSON) { p.Alternativas[3] = "   " }, false},
+		{"alternativa só com a letra", func(p *models.PerguntaJSON) { p.Alternativas[0] = "A) " }, false},
+		{"resposta fora de A a D", func(p *models.PerguntaJSON) { p.Resposta = "E" }, false},
+		{"resposta com duas letras", func(p *models.PerguntaJSON) { p.Resposta = "AB" }, false},
+		{"tradução sem enunciado", func(p *models.PerguntaJSON) {
+			p.Traducoes["en-US"] = models.TraducaoPergunta{Alternativas: []string{"Venus", "Earth", "Mercury", "Mars"}}
+		}, false},
+		{"tradução com alternativas a menos", func(p *models.PerguntaJSON) {
+			p.Traducoes["en-US"] = models.TraducaoPergunta{Enunciado: "Closest planet?", Alternativas: []string{"Venus"}}
+		}, false},
+		{"tradução com alternativa em branco", func(p *models.PerguntaJSON) {
+			p.Traducoes["en-US"] = models.TraducaoPergunta{Enunciado: "Closest planet?", Alternativas: []string{"Venus", " ", "Mercury", "Mars"}}
+		}, false},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			pergunta := perguntaValida()
+			caso.mudar(&pergunta)
+			if err := validarPergunta(pergunta); (err == nil) != caso.valida {
+				t.Fatalf("validarPergunta = %v, esperava válida = %v", err, caso.valida)
+			}
+		})
+	}
+}
+
+// escreverPacote grava o conteúdo como JSON no diretório
+func escreverPacote(t *testing.T, diretorio, arquivo string, conteudo any) {
+	t.Helper()
+	arquivoBytes, err := json.Marshal(conteudo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(diretorio, arquivo), arquivoBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNovoBancoCarregaPacotes(t *testing.T) {
+	diretorio := t.TempDir()
+	invalida := perguntaValida()
+	invalida.Resposta = "E"
+	escreverPacote(t, diretorio, "lista.json", []models.PerguntaJSON{perguntaValida()})
+	escreverPacote(t, diretorio, "meta.json", arquivoPacote{Nome: "ciencia", Descricao: "Planetas", Perguntas: []models.PerguntaJSON{perguntaValida()}})
+	escreverPacote(t, diretorio, "vazio.json", []models.PerguntaJSON{})
+	escreverPacote(t, diretorio, "invalida.json", []models.PerguntaJSON{perguntaValida(), invalida})
+	escreverPacote(t, diretorio, "zz.json", arquivoPacote{Nome: "ciencia", Perguntas: []models.PerguntaJSON{perguntaValida()}})
+	os.WriteFile(filepath.Join(diretorio, "quebrado.json"), []byte(`[{"enunciado":`), 0644)
+	os.WriteFile(filepath.Join(diretorio, "notas.txt"), []byte("não é pacote"), 0644)
+
+	banco, err := NovoBanco(diretorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pacotes := banco.Pacotes(); !reflect.DeepEqual(pacotes, []string{"ciencia", "lista"}) {
+		t.Fatalf("Pacotes = %v, esperava [ciencia lista]", pacotes)
+	}
+	if ciencia, _ := banco.Pacote("ciencia"); ciencia.Descricao != "Planetas" || filepath.Base(ciencia.Arquivo) != "meta.json" {
+		t.Fatalf("ciencia = %+v, esperava o pacote de meta.json com a descrição", ciencia)
+	}
+
+	erros := banco.Erros()
+	for _, arquivo := range []string{"vazio.json", "invalida.json", "zz.json", "quebrado.json"} {
+		if erros[arquivo] == nil {
+			t.Errorf("%s deveria ter erro; erros = %v", arquivo, erros)
+		}
+	}
+	if len(erros) != 4 {
+		t.Fatalf("erros = %v, esperava só os quatro pacotes inválidos", erros)
+	}
+}
+
+func TestNovoBancoSemDiretorio(t *testing.T) {
+	diretorio := t.TempDir()
+	if _, err := NovoBanco(filepath.Join(diretorio, "nao_existe")); err == nil {
+		t.Fatal("NovoBanco aceitou um diretório que não existe")
+	}
+	arquivo := filepath.Join(diretorio, "pacote.json")
+	escreverPacote(t, diretorio, "pacote.json", []models.PerguntaJSON{perguntaValida()})
+	if _, err := NovoBanco(arquivo); err == nil {
+		t.Fatal("NovoBanco aceitou um arquivo no lugar do diretório")
+	}
+}
+
+func TestSortear(t *testing.T) {
+	diretorio := t.TempDir()
+	antiga := perguntaValida()
+	antiga.Alternativas = []string{"A) Vênus", "B) Terra", "C) Mercúrio", "D) Marte"}
+	antiga.Resposta = "c"
+	escreverPacote(t, diretorio, "antigo.json", []models.PerguntaJSON{antiga})
+	outra := perguntaValida()
+	outra.Enunciado = "Qual é o maior planeta?"
+	outra.Resposta = "D"
+	escreverPacote(t, diretorio, "outro.json", []models.PerguntaJSON{outra})
+	banco, err := NovoBanco(diretorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perguntas, err := banco.Sortear([]string{"antigo"}, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pergunta := perguntas[0]
+	if pergunta.ID != 1 || pergunta.Correta != 2 || !reflect.DeepEqual(pergunta.Opcoes, []string{"Vênus", "Terra", "Mercúrio", "Marte"}) {
+		t.Fatalf("pergunta = %+v, esperava as alternativas sem a letra e a correta no índice 2", pergunta)
+	}
+	if traducao := pergunta.Traducoes["en-US"]; traducao.Alternativas[2] != "Mercury" {
+		t.Fatalf("tradução = %+v", traducao)
+	}
+
+	// Sem pacotes escolhidos vale o banco todo, com IDs sequenciais e até o limite
+	if perguntas, _ := banco.Sortear(nil, 0, nil); len(perguntas) != 2 || perguntas[0].ID != 1 || perguntas[1].ID != 2 {
+		t.Fatalf("Sortear(nil) = %+v, esperava as duas perguntas com IDs 1 e 2", perguntas)
+	}
+	if perguntas, _ := banco.Sortear(nil, 1, nil); len(perguntas) != 1 {
+		t.Fatalf("Sortear com limite 1 trouxe %d perguntas", len(perguntas))
+	}
+
+	// Um pacote repetido na escolha entra uma vez só
+	if perguntas, _ := banco.Sortear([]string{"antigo", "antigo", "antigo"}, 0, nil); len(perguntas) != 1 {
+		t.Fatalf("o pacote repetido trouxe %d perguntas, esperava 1", len(perguntas))
+	}
+
+	if _, err := banco.Sortear([]string{"nao_existe"}, 0, nil); err == nil {
+		t.Fatal("Sortear aceitou um pacote que não existe")
+	}
+}
+
+func TestAdicionar(t *testing.T) {
+	diretorio := t.TempDir()
+	escreverPacote(t, diretorio, "ciencia.json", []models.PerguntaJSON{perguntaValida()})
+	banco, err := NovoBanco(diretorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conteudo, _ := json.Marshal([]models.PerguntaJSON{perguntaValida()})
+
+	// O nome ganha .json e perde qualquer diretório
+	pacote, err := banco.Adicionar("../planetas", conteudo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pacote.Nome != "planetas" || pacote.Arquivo != filepath.Join(diretorio, "planetas.json") {
+		t.Fatalf("pacote = %+v, esperava planetas.json no diretório do banco", pacote)
+	}
+	if _, ok := banco.Pacote("planetas"); !ok {
+		t.Fatal("o banco não recarregou o pacote adicionado")
+	}
+	if _, err := os.Stat(pacote.Arquivo + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("sobrou o temporário: %v", err)
+	}
+
+	// Substituir o mesmo arquivo vale
+	if _, err := banco.Adicionar("planetas.json", conteudo); err != nil {
+		t.Fatal(err)
+	}
+
+	recusados := []struct {
+		nome     string
+		arquivo  string
+		conteudo []byte
+	}{
+		{"arquivo oculto", ".escondido", conteudo},
+		{"conteúdo inválido", "quebrado", []byte(`[{"enunciado":"?"}]`)},
+		{"nome de outro pacote", "outro", []byte(`{"nome":"ciencia","perguntas":` + string(conteudo) + `}`)},
+	}
+	for _, caso := range recusados {
+		t.Run(caso.nome, func(t *testing.T) {
+			if _, err := banco.Adicionar(caso.arquivo, caso.conteudo); err == nil {
+				t.Fatal("Adicionar aceitou o pacote")
+			}
+			arquivos, _ := filepath.Glob(filepath.Join(diretorio, "*"))
+			if len(arquivos) != 2 {
+				t.Fatalf("arquivos = %v, esperava só ciencia.json e planetas.json", arquivos)
+			}
+		})
+	}
+}
+
+func TestObservarRecarregaOBanco(t *testing.T) {
+	diretorio := t.TempDir()
+	escreverPacote(t, diretorio, "ciencia.json", []models.PerguntaJSON{perguntaValida()})
+	banco, err := NovoBanco(diretorio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := banco.Observar(); err != nil {
+		t.Fatal(err)
+	}
+	defer banco.Parar()
+
+	escreverPacote(t, diretorio, "historia.json", []models.PerguntaJSON{perguntaValida()})
+	os.Remove(filepath.Join(diretorio, "ciencia.json"))
+
+	limite := time.Now().Add(5 * time.Second)
+	for !reflect.DeepEqual(banco.Pacotes(), []string{"historia"}) {
+		if time.Now().After(limite) {
+			t.Fatalf("Pacotes = %v, esperava [historia] depois da mudança no diretório", banco.Pacotes())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
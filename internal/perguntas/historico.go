@@ -0,0 +1,125 @@
+// Histórico das perguntas já feitas a cada jogador, salvo em disco entre execuções
+
+package perguntas
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Historico guarda quando cada jogador viu cada pergunta pela última vez
+type Historico struct {
+	arquivo  string
+	cooldown time.Duration
+	vistas   map[string]map[string]time.Time // jogador -> chave da pergunta -> última vez
+	mutex    *sync.Mutex
+}
+
+// NovoHistorico carrega o histórico do arquivo, se existir.
+// Perguntas vistas há menos que o cooldown são evitadas nas próximas partidas.
+func NovoHistorico(arquivo string, cooldown time.Duration) (*Historico, error) {
+	historico := &Historico{
+		arquivo:  arquivo,
+		cooldown: cooldown,
+		vistas:   make(map[string]map[string]time.Time),
+		mutex:    &sync.Mutex{},
+	}
+
+	arquivoBytes, err := os.ReadFile(arquivo)
+	if errors.Is(err, os.ErrNotExist) {
+		return historico, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o histórico: %w", err)
+	}
+	if err := json.Unmarshal(arquivoBytes, &historico.vistas); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar o histórico: %w", err)
+	}
+	return historico, nil
+}
+
+// chavePergunta identifica a pergunta pelo enunciado, para sobreviver a mudanças de ordem nos pacotes
+func chavePergunta(pergunta models.PerguntaJSON) string {
+	soma := sha1.Sum([]byte(strings.TrimSpace(pergunta.Enunciado)))
+	return hex.EncodeToString(soma[:8])
+}
+
+// chaveJogador normaliza o nome para o mesmo jogador ser reconhecido entre partidas
+func chaveJogador(nome string) string {
+	return strings.ToLower(strings.TrimSpace(nome))
+}
+
+// UltimaVez retorna a vez mais recente em que algum dos jogadores viu a pergunta
+func (historico *Historico) UltimaVez(pergunta models.PerguntaJSON, jogadores []string) time.Time {
+	historico.mutex.Lock()
+	defer historico.mutex.Unlock()
+
+	chave := chavePergunta(pergunta)
+	var ultima time.Time
+	for _, jogador := range jogadores {
+		if vista, ok := historico.vistas[chaveJogador(jogador)][chave]; ok && vista.After(ultima) {
+			ultima = vista
+		}
+	}
+	return ultima
+}
+
+// Recente informa se a pergunta ainda está no cooldown para o grupo de jogadores
+func (historico *Historico) Recente(pergunta models.PerguntaJSON, jogadores []string) bool {
+	ultima := historico.UltimaVez(pergunta, jogadores)
+	return !ultima.IsZero() && time.Since(ultima) < historico.cooldown
+}
+
+// Registrar marca as perguntas como vistas pelos jogadores, só na memória; o disco fica para Salvar
+func (historico *Historico) Registrar(perguntas []models.PerguntaJSON, jogadores []string) {
+	historico.mutex.Lock()
+	agora := time.Now()
+	for _, jogador := range jogadores {
+		chave := chaveJogador(jogador)
+		if historico.vistas[chave] == nil {
+			historico.vistas[chave] = make(map[string]time.Time)
+		}
+		for _, pergunta := range perguntas {
+			historico.vistas[chave][chavePergunta(pergunta)] = agora
+		}
+	}
+	historico.mutex.Unlock()
+}
+
+// Salvar grava o histórico em disco, descartando o que já saiu do cooldown
+func (historico *Historico) Salvar() error {
+	historico.mutex.Lock()
+	for jogador, vistas := range historico.vistas {
+		for chave, vista := range vistas {
+			if time.Since(vista) >= historico.cooldown {
+				delete(vistas, chave)
+			}
+		}
+		if len(vistas) == 0 {
+			delete(historico.vistas, jogador)
+		}
+	}
+	arquivoBytes, err := json.MarshalIndent(historico.vistas, "", "  ")
+	historico.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("erro ao codificar o histórico: %w", err)
+	}
+
+	// Escreve num arquivo temporário e renomeia, para não corromper o histórico se o servidor cair
+	temporario := historico.arquivo + ".tmp"
+	if err := os.WriteFile(temporario, arquivoBytes, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar o histórico: %w", err)
+	}
+	if err := os.Rename(temporario, historico.arquivo); err != nil {
+		return fmt.Errorf("erro ao salvar o histórico: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package perguntas
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+func TestHistoricoCooldown(t *testing.T) {
+	historico, err := NovoHistorico(filepath.Join(t.TempDir(), "historico.json"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pergunta := perguntaValida()
+	if !historico.UltimaVez(pergunta, []string{"ana"}).IsZero() || historico.Recente(pergunta, []string{"ana"}) {
+		t.Fatal("pergunta nunca vista aparece como recente")
+	}
+
+	historico.Registrar([]models.PerguntaJSON{pergunta}, []string{"Ana"})
+
+	// O nome é normalizado, e basta um jogador do grupo ter visto
+	if !historico.Recente(pergunta, []string{"bia", " ana "}) {
+		t.Fatal("pergunta vista por ana não aparece como recente")
+	}
+	if historico.Recente(pergunta, []string{"bia"}) {
+		t.Fatal("pergunta aparece como recente para quem não a viu")
+	}
+	// A chave é o enunciado: a mesma pergunta com as alternativas em outra ordem continua vista
+	reordenada := pergunta
+	reordenada.Alternativas = []string{"Marte", "Mercúrio", "Terra", "Vênus"}
+	if !historico.Recente(reordenada, []string{"ana"}) {
+		t.Fatal("a pergunta com as alternativas em outra ordem deixou de ser recente")
+	}
+
+	// Passado o cooldown, a pergunta volta
+	historico.vistas["ana"][chavePergunta(pergunta)] = time.Now().Add(-2 * time.Hour)
+	if historico.Recente(pergunta, []string{"ana"}) {
+		t.Fatal("a pergunta continua recente depois do cooldown")
+	}
+}
+
+func TestHistoricoSalvarECarregar(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), "historico.json")
+	historico, err := NovoHistorico(arquivo, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recente, antiga := perguntaValida(), perguntaValida()
+	antiga.Enunciado = "Qual é o maior planeta?"
+	historico.Registrar([]models.PerguntaJSON{recente, antiga}, []string{"ana", "bia"})
+	historico.vistas["bia"][chavePergunta(recente)] = time.Now().Add(-2 * time.Hour)
+	historico.vistas["bia"][chavePergunta(antiga)] = time.Now().Add(-2 * time.Hour)
+	historico.vistas["ana"][chavePergunta(antiga)] = time.Now().Add(-2 * time.Hour)
+
+	// Registrar fica na memória
+	if _, err := os.Stat(arquivo); !os.IsNotExist(err) {
+		t.Fatalf("Registrar gravou o arquivo: %v", err)
+	}
+	if err := historico.Salvar(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(arquivo + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("sobrou o temporário: %v", err)
+	}
+
+	carregado, err := NovoHistorico(arquivo, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !carregado.Recente(recente, []string{"ana"}) {
+		t.Fatal("o histórico carregado perdeu a pergunta recente")
+	}
+	// O que saiu do cooldown não é gravado, nem o jogador que ficou sem vistas
+	if len(carregado.vistas["ana"]) != 1 {
+		t.Fatalf("vistas de ana = %v, esperava só a pergunta recente", carregado.vistas["ana"])
+	}
+	if _, ok := carregado.vistas["bia"]; ok {
+		t.Fatalf("bia continua no histórico: %v", carregado.vistas["bia"])
+	}
+}
+
+func TestNovoHistoricoArquivoInvalido(t *testing.T) {
+	arquivo := filepath.Join(t.TempDir(), "historico.json")
+	if err := os.WriteFile(arquivo, []byte("{quebrado"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NovoHistorico(arquivo, time.Hour); err == nil {
+		t.Fatal("NovoHistorico aceitou um arquivo corrompido")
+	}
+}
+
+func TestPriorizarNaoVistas(t *testing.T) {
+	historico, err := NovoHistorico(filepath.Join(t.TempDir(), "historico.json"), time.Hour*24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	banco := &Banco{historico: historico}
+	perguntas := make([]models.PerguntaJSON, 4)
+	for i := range perguntas {
+		perguntas[i] = perguntaValida()
+		perguntas[i].Enunciado = fmt.Sprintf("Pergunta %d", i+1)
+	}
+	// bia viu a 1 há uma hora e a 2 há duas; a 3 foi vista por quem não está jogando
+	agora := time.Now()
+	historico.vistas = map[string]map[string]time.Time{
+		"bia":  {chavePergunta(perguntas[0]): agora.Add(-time.Hour), chavePergunta(perguntas[1]): agora.Add(-2 * time.Hour)},
+		"caio": {chavePergunta(perguntas[2]): agora},
+	}
+
+	ordem := banco.priorizarNaoVistas(perguntas, []string{"ana", "Bia "})
+	var enunciados []string
+	for _, pergunta := range ordem {
+		enunciados = append(enunciados, pergunta.Enunciado)
+	}
+	esperado := []string{"Pergunta 3", "Pergunta 4", "Pergunta 2", "Pergunta 1"}
+	if !reflect.DeepEqual(enunciados, esperado) {
+		t.Fatalf("ordem = %v, esperava %v", enunciados, esperado)
+	}
+}
@@ -0,0 +1,63 @@
+// Registro estruturado (log/slog) do servidor: formato, nível e arquivo de saída
+
+package registro
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Config escolhe como e onde os registros são escritos
+type Config struct {
+	Formato       string // "texto" ou "json"
+	Nivel         string // "debug", "info", "warn" ou "error"
+	Arquivo       string // vazio escreve no stderr
+	TamanhoMaximo int64  // bytes antes de rodar o arquivo
+	Copias        int    // arquivos antigos mantidos
+}
+
+// Novo cria o logger descrito pela configuração. O io.Closer fecha o arquivo, se houver.
+func Novo(config Config) (*slog.Logger, io.Closer, error) {
+	nivel, err := Nivel(config.Nivel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var saida io.Writer = os.Stderr
+	var fechar io.Closer = semArquivo{}
+	if config.Arquivo != "" {
+		arquivo, err := NovoArquivoRotativo(config.Arquivo, config.TamanhoMaximo, config.Copias)
+		if err != nil {
+			return nil, nil, err
+		}
+		saida, fechar = arquivo, arquivo
+	}
+
+	opcoes := &slog.HandlerOptions{Level: nivel}
+	switch strings.ToLower(config.Formato) {
+	case "", "texto", "text":
+		return slog.New(slog.NewTextHandler(saida, opcoes)), fechar, nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(saida, opcoes)), fechar, nil
+	default:
+		fechar.Close()
+		return nil, nil, fmt.Errorf("formato de log inválido: %q (use texto ou json)", config.Formato)
+	}
+}
+
+// Nivel converte o nome do nível ("debug", "info", "warn", "error") para o slog
+func Nivel(nome string) (slog.Level, error) {
+	var nivel slog.Level
+	if err := nivel.UnmarshalText([]byte(nome)); err != nil {
+		return 0, fmt.Errorf("nível de log inválido: %q (use debug, info, warn ou error)", nome)
+	}
+	return nivel, nil
+}
+
+// semArquivo é o io.Closer de quando o log vai para o stderr
+type semArquivo struct{}
+
+func (semArquivo) Close() error { return nil }
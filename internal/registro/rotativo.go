@@ -0,0 +1,114 @@
+// Arquivo de log que roda quando passa do tamanho máximo: log → log.1 → log.2 ...
+
+package registro
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// ArquivoRotativo é um io.Writer que troca de arquivo ao atingir o tamanho máximo,
+// mantendo as cópias mais recentes com sufixo numérico
+type ArquivoRotativo struct {
+	caminho       string
+	tamanhoMaximo int64
+	copias        int
+	arquivo       *os.File
+	tamanho       int64
+	mutex         *sync.Mutex
+}
+
+// NovoArquivoRotativo abre (ou cria) o arquivo de log para acrescentar registros
+func NovoArquivoRotativo(caminho string, tamanhoMaximo int64, copias int) (*ArquivoRotativo, error) {
+	rotativo := &ArquivoRotativo{
+		caminho:       caminho,
+		tamanhoMaximo: tamanhoMaximo,
+		copias:        copias,
+		mutex:         &sync.Mutex{},
+	}
+	if err := rotativo.abrir(); err != nil {
+		return nil, err
+	}
+	return rotativo, nil
+}
+
+// abrir abre o arquivo atual e lê o tamanho que ele já tem
+func (rotativo *ArquivoRotativo) abrir() error {
+	arquivo, err := os.OpenFile(rotativo.caminho, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir o arquivo de log: %w", err)
+	}
+	info, err := arquivo.Stat()
+	if err != nil {
+		arquivo.Close()
+		return fmt.Errorf("erro ao abrir o arquivo de log: %w", err)
+	}
+	rotativo.arquivo = arquivo
+	rotativo.tamanho = info.Size()
+	return nil
+}
+
+// Write escreve o registro, rodando o arquivo antes se ele for passar do limite. Se a rotação
+// falhar, o erro vai para o stderr e o registro continua no arquivo atual.
+func (rotativo *ArquivoRotativo) Write(p []byte) (int, error) {
+	rotativo.mutex.Lock()
+	defer rotativo.mutex.Unlock()
+
+	if rotativo.tamanhoMaximo > 0 && rotativo.tamanho > 0 && rotativo.tamanho+int64(len(p)) > rotativo.tamanhoMaximo {
+		if err := rotativo.rodar(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	n, err := rotativo.arquivo.Write(p)
+	rotativo.tamanho += int64(n)
+	return n, err
+}
+
+// rodar fecha o arquivo atual, empurra as cópias (log.1 vira log.2...) e abre um arquivo novo.
+// Se não conseguir mover os arquivos, reabre o arquivo atual para os registros não se perderem.
+func (rotativo *ArquivoRotativo) rodar() error {
+	rotativo.arquivo.Close()
+
+	if err := rotativo.moverCopias(); err != nil {
+		if errAbrir := rotativo.abrir(); errAbrir != nil {
+			return errors.Join(err, errAbrir)
+		}
+		return err
+	}
+	return rotativo.abrir()
+}
+
+// moverCopias renomeia as cópias antigas e o arquivo atual, ou apaga o atual se não houver cópias.
+// Cópias que ainda não existem não são erro.
+func (rotativo *ArquivoRotativo) moverCopias() error {
+	if rotativo.copias <= 0 {
+		if err := os.Remove(rotativo.caminho); err != nil {
+			return fmt.Errorf("erro ao rodar o arquivo de log: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.Remove(fmt.Sprintf("%s.%d", rotativo.caminho, rotativo.copias)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("erro ao rodar o arquivo de log: %w", err)
+	}
+	for i := rotativo.copias - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", rotativo.caminho, i), fmt.Sprintf("%s.%d", rotativo.caminho, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("erro ao rodar o arquivo de log: %w", err)
+		}
+	}
+	if err := os.Rename(rotativo.caminho, rotativo.caminho+".1"); err != nil {
+		return fmt.Errorf("erro ao rodar o arquivo de log: %w", err)
+	}
+	return nil
+}
+
+// Close fecha o arquivo atual
+func (rotativo *ArquivoRotativo) Close() error {
+	rotativo.mutex.Lock()
+	defer rotativo.mutex.Unlock()
+	return rotativo.arquivo.Close()
+}
@@ -2,32 +2,64 @@ package server
 
 import (
 	"sort" //Funcionalidades de ordenação de dados
-	"strings"
+	"time"
 	"triviaMultiplayer/internal/models"
 )
 
+// CompensacaoMaxima limita o desconto de latência, para quem atrasa os pongs de propósito não ganhar vantagem
+const CompensacaoMaxima = 250 * time.Millisecond
+
+// Desempate são as regras para ordenar quem acertou
+type Desempate struct {
+	CompensarLatencia bool          // desconta metade do tempo de ida e volta de cada jogador
+	Empate            time.Duration // respostas a até esse tempo da primeira do grupo empatam
+}
+
+// momentoResposta é quando a resposta chegou, descontada a latência do jogador se pedido
+func momentoResposta(resp models.Resposta, desempate Desempate) time.Time {
+	if !desempate.CompensarLatencia || resp.RTT <= 0 {
+		return resp.Tempo
+	}
+	compensacao := resp.RTT / 2
+	if compensacao > CompensacaoMaxima {
+		compensacao = CompensacaoMaxima
+	}
+	return resp.Tempo.Add(-compensacao)
+}
+
 // CalcularPontos calcula a pontuação com base nas respostas
-// O primeiro a acertar ganha 100, e cada subsequente ganha metade da pontuação anterior
-func CalcularPontos(respostas []models.Resposta, opcaoCorreta string) []models.Pontuacao {
+// O primeiro a acertar ganha 100, e cada subsequente ganha metade da pontuação anterior.
+// Empatados dividem a mesma posição e recebem os mesmos pontos; a posição seguinte pula os empatados.
+func CalcularPontos(respostas []models.Resposta, desempate Desempate) []models.Pontuacao {
 	var respostasCorretas []models.Resposta
 	for _, resp := range respostas {
-		// A opção vem como "A) Paris", então pegamos apenas a letra.
-		if strings.HasPrefix(resp.Opcao, opcaoCorreta) {
+		// A resposta foi avaliada por AvaliarResposta na leitura, a mesma avaliação do feedback
+		if resp.Correta {
 			respostasCorretas = append(respostasCorretas, resp)
 		}
 	}
 
-	// Ordena as respostas corretas pelo tempo
-	sort.Slice(respostasCorretas, func(i, j int) bool {
-		return respostasCorretas[i].Tempo.Before(respostasCorretas[j].Tempo)
+	// Ordena as respostas corretas pelo momento de chegada
+	sort.SliceStable(respostasCorretas, func(i, j int) bool {
+		return momentoResposta(respostasCorretas[i], desempate).Before(momentoResposta(respostasCorretas[j], desempate))
 	})
 
 	var listaPontos []models.Pontuacao
 	pontosAtuais := 100 // Pontuação inicial
 
-	for _, resp := range respostasCorretas {
-		listaPontos = append(listaPontos, models.Pontuacao{Jogador: resp.Jogador, Pontos: pontosAtuais})
-		pontosAtuais /= 2 // Metade para o próximo
+	for i := 0; i < len(respostasCorretas); {
+		// O grupo vai da primeira resposta até a última a menos de Empate dela
+		primeira := momentoResposta(respostasCorretas[i], desempate)
+		fim := i + 1
+		for fim < len(respostasCorretas) && momentoResposta(respostasCorretas[fim], desempate).Sub(primeira) <= desempate.Empate {
+			fim++
+		}
+		for _, resp := range respostasCorretas[i:fim] {
+			listaPontos = append(listaPontos, models.Pontuacao{Jogador: resp.Jogador, Pontos: pontosAtuais})
+		}
+		for ; i < fim; i++ {
+			pontosAtuais /= 2 // Metade para o próximo
+		}
 	}
 
 	return listaPontos
@@ -0,0 +1,363 @@
+// API de administração: jogadores, banimentos, partida, regras e pacotes, protegida por token
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"triviaMultiplayer/internal/idioma"
+)
+
+// Tamanho máximo de um pacote enviado pelo painel
+const tamanhoMaximoPacote = 1 << 20
+
+// ErrJogadorNaoEncontrado indica que nenhum jogador conectado tem o nome pedido
+var ErrJogadorNaoEncontrado = errors.New("jogador não encontrado")
+
+// JogadorAdmin é um jogador como mostrado no painel
+type JogadorAdmin struct {
+	Nome   string `json:"nome"`
+	Idioma string `json:"idioma"`
+	IP     string `json:"ip"`
+	Pontos int    `json:"pontos"`
+}
+
+// PacoteAdmin é um pacote de perguntas como mostrado no painel
+type PacoteAdmin struct {
+	Nome      string `json:"nome"`
+	Descricao string `json:"descricao"`
+	Perguntas int    `json:"perguntas"`
+}
+
+// GerarTokenAdmin sorteia um token para quando o host não escolhe um
+func GerarTokenAdmin() string {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(token)
+}
+
+// hostDe tira a porta de um endereço "IP:porta"
+func hostDe(endereco string) string {
+	host, _, err := net.SplitHostPort(endereco)
+	if err != nil {
+		return endereco
+	}
+	return host
+}
+
+// Expulsar desconecta os jogadores com o nome informado. Com banir, o IP deles não pode mais entrar.
+func (server *ServerJogo) Expulsar(nome string, banir bool) (int, error) {
+	server.jogadoresMutex.Lock()
+	var expulsos []*Jogador
+	restantes := make([]*Jogador, 0, len(server.jogadores))
+	for _, jogador := range server.jogadores {
+		if jogador.Nome != nome {
+			restantes = append(restantes, jogador)
+			continue
+		}
+		expulsos = append(expulsos, jogador)
+		if banir {
+			server.banidos[jogador.IP] = true
+		}
+	}
+	server.jogadores = restantes
+	server.jogadoresMutex.Unlock()
+
+	if len(expulsos) == 0 {
+		return 0, ErrJogadorNaoEncontrado
+	}
+	for _, jogador := range expulsos {
+		server.comPartida(jogador.log).Info("jogador expulso", "banido", banir)
+		if banir {
+			fmt.Println(idioma.T("JogadorBanido", idioma.Dados{"Nome": jogador.Nome, "IP": jogador.IP}))
+			server.enviar(jogador, []byte("{\"tipo\":\"banido\"}\n"))
+		} else {
+			fmt.Println(idioma.T("JogadorExpulso", idioma.Dados{"Nome": jogador.Nome}))
+			server.enviar(jogador, []byte("{\"tipo\":\"expulso\"}\n"))
+		}
+		jogador.encerrar() // controlaCliente fecha a conexão depois de enviar o aviso
+	}
+	return len(expulsos), nil
+}
+
+// Desbanir libera um IP banido
+func (server *ServerJogo) Desbanir(ip string) {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+	delete(server.banidos, ip)
+}
+
+// Banidos retorna os IPs banidos em ordem
+func (server *ServerJogo) Banidos() []string {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	ips := make([]string, 0, len(server.banidos))
+	for ip := range server.banidos {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// banido informa se o IP está banido
+func (server *ServerJogo) banido(ip string) bool {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+	return server.banidos[ip]
+}
+
+// HabilitarAdmin registra o painel em /admin/ e a API em /admin/api/ no servidor HTTP.
+// Toda chamada à API precisa do cabeçalho "Authorization: Bearer <token>".
+func (server *ServerJogo) HabilitarAdmin(token string) {
+	painel, _ := arquivosWeb.ReadFile("web/admin.html")
+	server.mux.HandleFunc("/admin/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(painel)
+	})
+
+	rotas := map[string]http.HandlerFunc{
+		"/admin/api/jogadores":       server.apiJogadores,
+		"/admin/api/expulsar":        server.apiExpulsar,
+		"/admin/api/banidos":         server.apiBanidos,
+		"/admin/api/desbanir":        server.apiDesbanir,
+		"/admin/api/partida":         server.apiPartida,
+		"/admin/api/partida/iniciar": server.apiIniciar,
+		"/admin/api/partida/pausar":  server.apiComando(server.Pausar),
+		"/admin/api/partida/retomar": server.apiComando(server.Retomar),
+		"/admin/api/partida/pular":   server.apiComando(server.Pular),
+		"/admin/api/partida/abortar": server.apiComando(server.Abortar),
+		"/admin/api/config":          server.apiConfig,
+		"/admin/api/pacotes":         server.apiPacotes,
+	}
+	for caminho, tratador := range rotas {
+		server.mux.Handle(caminho, autenticar(token, tratador))
+	}
+}
+
+// autenticar só deixa passar as requisições com o token de administração
+func autenticar(token string, proximo http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebido := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(recebido), []byte(token)) != 1 {
+			responderErro(w, http.StatusUnauthorized, errors.New("token de administração inválido"))
+			return
+		}
+		proximo(w, r)
+	})
+}
+
+// responderJSON escreve o valor como JSON com o status informado
+func responderJSON(w http.ResponseWriter, status int, valor interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(valor)
+}
+
+// responderErro escreve {"erro": "..."} com o status informado
+func responderErro(w http.ResponseWriter, status int, err error) {
+	responderJSON(w, status, map[string]string{"erro": err.Error()})
+}
+
+// statusDoErro traduz os erros da partida em códigos HTTP
+func statusDoErro(err error) int {
+	switch {
+	case errors.Is(err, ErrPartidaEmAndamento), errors.Is(err, ErrSemPartida), errors.Is(err, ErrSemJogadores):
+		return http.StatusConflict
+	case errors.Is(err, ErrJogadorNaoEncontrado):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// metodoPermitido responde 405 se o método não for o esperado
+func metodoPermitido(w http.ResponseWriter, r *http.Request, metodo string) bool {
+	if r.Method != metodo {
+		w.Header().Set("Allow", metodo)
+		responderErro(w, http.StatusMethodNotAllowed, fmt.Errorf("use %s", metodo))
+		return false
+	}
+	return true
+}
+
+// GET /admin/api/jogadores
+func (server *ServerJogo) apiJogadores(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodGet) {
+		return
+	}
+	// Os pontos mudam durante a partida, então a lista é montada com jogadoresMutex travado
+	server.jogadoresMutex.Lock()
+	jogadores := make([]JogadorAdmin, 0, len(server.jogadores))
+	for _, jogador := range server.jogadores {
+		jogadores = append(jogadores, JogadorAdmin{Nome: jogador.Nome, Idioma: jogador.Idioma, IP: jogador.IP, Pontos: jogador.Pontuacao})
+	}
+	server.jogadoresMutex.Unlock()
+	responderJSON(w, http.StatusOK, jogadores)
+}
+
+// POST /admin/api/expulsar {"nome": "...", "banir": true}
+func (server *ServerJogo) apiExpulsar(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodPost) {
+		return
+	}
+	var pedido struct {
+		Nome  string `json:"nome"`
+		Banir bool   `json:"banir"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&pedido); err != nil {
+		responderErro(w, http.StatusBadRequest, err)
+		return
+	}
+	expulsos, err := server.Expulsar(pedido.Nome, pedido.Banir)
+	if err != nil {
+		responderErro(w, statusDoErro(err), err)
+		return
+	}
+	responderJSON(w, http.StatusOK, map[string]int{"expulsos": expulsos})
+}
+
+// GET /admin/api/banidos
+func (server *ServerJogo) apiBanidos(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodGet) {
+		return
+	}
+	responderJSON(w, http.StatusOK, server.Banidos())
+}
+
+// POST /admin/api/desbanir {"ip": "..."}
+func (server *ServerJogo) apiDesbanir(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodPost) {
+		return
+	}
+	var pedido struct {
+		IP string `json:"ip"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&pedido); err != nil {
+		responderErro(w, http.StatusBadRequest, err)
+		return
+	}
+	server.Desbanir(pedido.IP)
+	responderJSON(w, http.StatusOK, server.Banidos())
+}
+
+// GET /admin/api/partida: estado da partida e placar ao vivo
+func (server *ServerJogo) apiPartida(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodGet) {
+		return
+	}
+	responderJSON(w, http.StatusOK, server.Estado())
+}
+
+// POST /admin/api/partida/iniciar {"pacotes": [...]} (corpo opcional)
+func (server *ServerJogo) apiIniciar(w http.ResponseWriter, r *http.Request) {
+	if !metodoPermitido(w, r, http.MethodPost) {
+		return
+	}
+	var pedido struct {
+		Pacotes []string `json:"pacotes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&pedido); err != nil && err != io.EOF {
+		responderErro(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := server.IniciarPartida(pedido.Pacotes); err != nil {
+		responderErro(w, statusDoErro(err), err)
+		return
+	}
+	responderJSON(w, http.StatusOK, server.Estado())
+}
+
+// apiComando trata pausar, retomar e abortar, que não têm corpo
+func (server *ServerJogo) apiComando(comando func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !metodoPermitido(w, r, http.MethodPost) {
+			return
+		}
+		if err := comando(); err != nil {
+			responderErro(w, statusDoErro(err), err)
+			return
+		}
+		responderJSON(w, http.StatusOK, server.Estado())
+	}
+}
+
+// GET e PUT /admin/api/config
+func (server *ServerJogo) apiConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		responderJSON(w, http.StatusOK, server.Config())
+	case http.MethodPut:
+		config := server.Config()
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			responderErro(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := server.DefinirConfig(config); err != nil {
+			responderErro(w, http.StatusBadRequest, err)
+			return
+		}
+		responderJSON(w, http.StatusOK, server.Config())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		responderErro(w, http.StatusMethodNotAllowed, errors.New("use GET ou PUT"))
+	}
+}
+
+// GET /admin/api/pacotes lista os pacotes e os arquivos inválidos;
+// POST /admin/api/pacotes?arquivo=nome.json grava o corpo como um novo pacote
+func (server *ServerJogo) apiPacotes(w http.ResponseWriter, r *http.Request) {
+	if server.banco == nil {
+		responderErro(w, http.StatusServiceUnavailable, errors.New("nenhum banco de perguntas definido"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		pacotes := make([]PacoteAdmin, 0)
+		for _, nome := range server.banco.Pacotes() {
+			pacote, _ := server.banco.Pacote(nome)
+			pacotes = append(pacotes, PacoteAdmin{Nome: pacote.Nome, Descricao: pacote.Descricao, Perguntas: len(pacote.Perguntas)})
+		}
+		erros := make(map[string]string)
+		for arquivo, err := range server.banco.Erros() {
+			erros[arquivo] = err.Error()
+		}
+		responderJSON(w, http.StatusOK, map[string]interface{}{"pacotes": pacotes, "erros": erros})
+	case http.MethodPost:
+		arquivo := r.URL.Query().Get("arquivo")
+		if arquivo == "" {
+			responderErro(w, http.StatusBadRequest, errors.New("informe o nome do arquivo em ?arquivo="))
+			return
+		}
+		conteudo, err := io.ReadAll(http.MaxBytesReader(w, r.Body, tamanhoMaximoPacote))
+		if err != nil {
+			responderErro(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		pacote, err := server.banco.Adicionar(arquivo, conteudo)
+		if err != nil {
+			responderErro(w, http.StatusBadRequest, err)
+			return
+		}
+		responderJSON(w, http.StatusCreated, PacoteAdmin{Nome: pacote.Nome, Descricao: pacote.Descricao, Perguntas: len(pacote.Perguntas)})
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		responderErro(w, http.StatusMethodNotAllowed, errors.New("use GET ou POST"))
+	}
+}
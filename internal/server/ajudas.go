@@ -0,0 +1,163 @@
+// Ajudas: cada jogador pode usar, a cada partida, algumas vezes cada ajuda na pergunta aberta,
+// antes de responder. O servidor confere o saldo e aplica o efeito na coleta e nos pontos.
+
+package server
+
+import (
+	"encoding/json"
+	"math/rand"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Ajudas disponíveis
+const (
+	AjudaMeioAMeio = "meio_a_meio" // tira duas alternativas erradas
+	AjudaMaisTempo = "mais_tempo"  // mais TempoExtraAjuda para responder
+	AjudaDobro     = "dobro"       // os pontos da pergunta valem o dobro
+	AjudaPular     = "pular"       // deixa a pergunta sem responder e sem perder vida
+)
+
+// TempoExtraAjuda é quanto a ajuda mais_tempo prolonga a pergunta para quem a usou
+const TempoExtraAjuda = 10 * time.Second
+
+// novasAjudas é o saldo de cada ajuda no começo da partida
+func novasAjudas(quantidade int) map[string]int {
+	if quantidade <= 0 {
+		return nil
+	}
+	return map[string]int{AjudaMeioAMeio: quantidade, AjudaMaisTempo: quantidade, AjudaDobro: quantidade, AjudaPular: quantidade}
+}
+
+// darAjudas começa a partida com o saldo de ajudas para cada jogador em jogo
+func (server *ServerJogo) darAjudas(quantidade int) {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	for _, jogador := range server.jogadores {
+		if !jogador.aguardando {
+			jogador.ajudas = novasAjudas(quantidade)
+		}
+	}
+}
+
+// usouAjuda informa se o jogador usou a ajuda na pergunta. Deve ser chamado com jogadoresMutex travado.
+func (atual *coleta) usouAjuda(jogador *Jogador, ajuda string) bool {
+	return atual.ajudas[jogador][ajuda]
+}
+
+// receberAjuda valida o pedido de ajuda e aplica o efeito: só na pergunta aberta, antes de
+// responder, uma vez por pergunta e enquanto o jogador tiver saldo daquela ajuda
+func (server *ServerJogo) receberAjuda(jogador *Jogador, linha []byte, recebida time.Time) {
+	var pedido models.Ajuda
+	if err := json.Unmarshal(linha, &pedido); err != nil {
+		server.rejeitar(jogador, motivoJSONInvalido, linha)
+		return
+	}
+
+	server.jogadoresMutex.Lock()
+	atual := server.coleta
+	motivo := ""
+	switch {
+	case atual == nil || atual.esgotou:
+		motivo = motivoSemPergunta
+	case jogador.aguardando, jogador.eliminado:
+		motivo = motivoForaDaPartida
+	case atual.pausada:
+		motivo = motivoPartidaPausada
+	case pedido.ID != atual.pergunta.ID:
+		motivo = motivoPerguntaErrada
+	case jogador.respondida == atual:
+		motivo = motivoRespostaRepetida
+	case atual.usouAjuda(jogador, pedido.Ajuda):
+		motivo = motivoAjudaRepetida
+	case atual.campainha || atual.pergunta.Final || jogador.ajudas[pedido.Ajuda] <= 0:
+		motivo = motivoAjudaInvalida // ajuda desconhecida, sem saldo, no modo campainha ou na rodada final
+	}
+	if motivo != "" {
+		server.jogadoresMutex.Unlock()
+		server.rejeitar(jogador, motivo, linha)
+		return
+	}
+
+	jogador.ajudas[pedido.Ajuda]--
+	if atual.ajudas == nil {
+		atual.ajudas = make(map[*Jogador]map[string]bool)
+	}
+	if atual.ajudas[jogador] == nil {
+		atual.ajudas[jogador] = make(map[string]bool)
+	}
+	atual.ajudas[jogador][pedido.Ajuda] = true
+	usada := models.AjudaUsada{Tipo: "ajuda_usada", ID: pedido.ID, Ajuda: pedido.Ajuda, Restantes: jogador.ajudas[pedido.Ajuda]}
+	switch pedido.Ajuda {
+	case AjudaMeioAMeio:
+		usada.Remover = alternativasRemovidas(atual.pergunta, jogador.ordem)
+		if atual.removidas == nil {
+			atual.removidas = make(map[*Jogador][]int)
+		}
+		for _, letra := range usada.Remover {
+			atual.removidas[jogador] = append(atual.removidas[jogador], jogador.ordem[letra[0]-'A'])
+		}
+	case AjudaMaisTempo:
+		usada.Tempo = segundosRestantes(TempoExtraAjuda)
+	case AjudaPular:
+		// Conta como respondida, sem alternativa: não pontua e não tira vida
+		jogador.respondida = atual
+	}
+	server.jogadoresMutex.Unlock()
+
+	server.comPartida(jogador.log).Debug("ajuda usada", "pergunta", pedido.ID, "ajuda", pedido.Ajuda)
+	msg, _ := json.Marshal(usada)
+	server.enviar(jogador, append(msg, '\n'))
+
+	if pedido.Ajuda == AjudaPular {
+		select {
+		case jogador.respostas <- models.Resposta{ID: pedido.ID, Alternativa: -1, Pulou: true, Tempo: recebida}:
+		default:
+			server.rejeitar(jogador, motivoRespostaRepetida, linha)
+		}
+	}
+}
+
+// removida informa se o meio_a_meio tirou a alternativa (índice canônico) da tela do jogador.
+// Deve ser chamado com jogadoresMutex travado.
+func (atual *coleta) removida(jogador *Jogador, alternativa int) bool {
+	for _, removida := range atual.removidas[jogador] {
+		if removida == alternativa {
+			return true
+		}
+	}
+	return false
+}
+
+// alternativasRemovidas sorteia duas alternativas erradas e retorna as letras delas na ordem vista pelo jogador
+func alternativasRemovidas(pergunta models.Pergunta, ordem []int) []string {
+	var erradas []string
+	for posicao, indice := range ordem {
+		if indice != pergunta.Correta {
+			erradas = append(erradas, string(rune('A'+posicao)))
+		}
+	}
+	rand.Shuffle(len(erradas), func(i, j int) { erradas[i], erradas[j] = erradas[j], erradas[i] })
+	if len(erradas) > 2 {
+		erradas = erradas[:2]
+	}
+	return erradas
+}
+
+// dobrarPontos aplica a ajuda dobro aos pontos de quem a usou na pergunta e acertou. No rei da
+// colina o rei pontua mesmo errando, mas o dobro só vale para um acerto.
+func dobrarPontos(pontos []models.Pontuacao, respostas []models.Resposta) []models.Pontuacao {
+	dobro := make(map[string]bool)
+	for _, resp := range respostas {
+		if resp.Dobro && resp.Correta {
+			dobro[resp.Jogador] = true
+		}
+	}
+	for i := range pontos {
+		if dobro[pontos[i].Jogador] {
+			pontos[i].Pontos *= 2
+		}
+	}
+	return pontos
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+func TestDobroSoValeComAcerto(t *testing.T) {
+	modo := &modoReiDaColina{}
+	modo.Preparar(nil, []string{"ana", "bia"}, ConfigPadrao())
+	emJogo := []*Jogador{{ID: 1, Nome: "ana"}, {ID: 2, Nome: "bia"}}
+	agora := time.Now()
+
+	// ana acerta primeiro e vira rei
+	modo.Pontuar([]models.Resposta{{Jogador: "ana", Correta: true, Tempo: agora}}, emJogo)
+
+	// O rei erra com dobro: segue pontuando pela coroa, mas sem dobrar
+	respostas := []models.Resposta{
+		{Jogador: "ana", Correta: false, Dobro: true, Tempo: agora},
+		{Jogador: "bia", Correta: false, Tempo: agora.Add(time.Second)},
+	}
+	rodada := modo.Pontuar(respostas, emJogo)
+	pontos := dobrarPontos(rodada.Pontos, respostas)
+	if len(pontos) != 1 || pontos[0].Jogador != "ana" || pontos[0].Pontos != PontosPorAcerto {
+		t.Fatalf("pontos = %v, esperava só ana com %d", pontos, PontosPorAcerto)
+	}
+
+	// Com acerto, o dobro vale
+	respostas = []models.Resposta{{Jogador: "ana", Correta: true, Dobro: true, Tempo: agora}}
+	rodada = modo.Pontuar(respostas, emJogo)
+	pontos = dobrarPontos(rodada.Pontos, respostas)
+	if len(pontos) != 1 || pontos[0].Pontos != 2*PontosPorAcerto {
+		t.Fatalf("pontos = %v, esperava ana com %d", pontos, 2*PontosPorAcerto)
+	}
+}
+
+func TestAlternativasRemovidas(t *testing.T) {
+	ordem := []int{3, 2, 0, 1} // a correta (Mercúrio) aparece na letra B
+	for i := 0; i < 50; i++ {
+		letras := alternativasRemovidas(perguntaTeste, ordem)
+		if len(letras) != 2 || letras[0] == letras[1] {
+			t.Fatalf("removidas = %v, esperava duas letras diferentes", letras)
+		}
+		for _, letra := range letras {
+			if letra != "A" && letra != "C" && letra != "D" {
+				t.Fatalf("removidas = %v, esperava só letras de alternativas erradas", letras)
+			}
+		}
+	}
+}
+
+// pedirAjuda faz o jogador pedir a ajuda em perguntaTeste
+func pedirAjuda(server *ServerJogo, jogador *Jogador, ajuda string) {
+	server.receberAjuda(jogador, []byte(`{"tipo":"ajuda","id":1,"ajuda":"`+ajuda+`"}`), time.Now())
+}
+
+func TestMeioAMeioRecusaAlternativaRemovida(t *testing.T) {
+	server := NovoServer(4)
+	auditoria := auditar(server)
+	ana, deAna := conectarTeste(t, server, "ana")
+	ana.ordem = []int{3, 2, 0, 1}
+	ana.ajudas = novasAjudas(1)
+	server.coleta = coletaTeste()
+
+	pedirAjuda(server, ana, AjudaMeioAMeio)
+	usada := esperarMensagem(t, deAna, "ajuda_usada")
+	if usada["restantes"] != float64(0) {
+		t.Fatalf("restantes = %v, esperava 0", usada["restantes"])
+	}
+	remover, _ := usada["remover"].([]any)
+	if len(remover) != 2 {
+		t.Fatalf("remover = %v, esperava duas letras", usada["remover"])
+	}
+
+	// As letras removidas são recusadas, na ordem embaralhada de ana
+	for _, letra := range remover {
+		server.receberResposta(ana, []byte(`{"tipo":"resposta","id":1,"opcao":"`+letra.(string)+`"}`), time.Now())
+		if motivo := auditoria.ultimo(); motivo != motivoOpcaoRemovida {
+			t.Fatalf("resposta %v: motivo = %q, esperava %q", letra, motivo, motivoOpcaoRemovida)
+		}
+	}
+	server.receberResposta(ana, []byte(`{"tipo":"resposta","id":1,"opcao":"B"}`), time.Now())
+	if resp := <-ana.respostas; !resp.Correta {
+		t.Fatalf("resposta = %+v, esperava o acerto na letra B", resp)
+	}
+
+	// Sem saldo, a ajuda não vale na próxima pergunta
+	server.coleta = coletaTeste()
+	pedirAjuda(server, ana, AjudaMeioAMeio)
+	if motivo := auditoria.ultimo(); motivo != motivoAjudaInvalida {
+		t.Fatalf("motivo = %q, esperava %q", motivo, motivoAjudaInvalida)
+	}
+}
+
+func TestReceberAjudaRecusada(t *testing.T) {
+	casos := []struct {
+		nome     string
+		preparar func(jogador *Jogador, atual *coleta)
+		motivo   string
+	}{
+		{"já usada na pergunta", func(jogador *Jogador, atual *coleta) {
+			atual.ajudas = map[*Jogador]map[string]bool{jogador: {AjudaDobro: true}}
+		}, motivoAjudaRepetida},
+		{"depois de responder", func(jogador *Jogador, atual *coleta) { jogador.respondida = atual }, motivoRespostaRepetida},
+		{"sem saldo", func(jogador *Jogador, atual *coleta) { jogador.ajudas[AjudaDobro] = 0 }, motivoAjudaInvalida},
+		{"modo campainha", func(jogador *Jogador, atual *coleta) { atual.campainha = true }, motivoAjudaInvalida},
+		{"rodada final", func(jogador *Jogador, atual *coleta) { atual.pergunta.Final = true }, motivoAjudaInvalida},
+		{"tempo esgotado", func(jogador *Jogador, atual *coleta) { atual.esgotou = true }, motivoSemPergunta},
+		{"partida pausada", func(jogador *Jogador, atual *coleta) { atual.pausada = true }, motivoPartidaPausada},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			server := NovoServer(4)
+			auditoria := auditar(server)
+			ana := jogadorTeste(1, "ana")
+			ana.ajudas = novasAjudas(1)
+			atual := coletaTeste()
+			server.coleta = atual
+			caso.preparar(ana, atual)
+
+			pedirAjuda(server, ana, AjudaDobro)
+			if motivo := auditoria.ultimo(); motivo != caso.motivo {
+				t.Fatalf("motivo = %q, esperava %q", motivo, caso.motivo)
+			}
+		})
+	}
+}
+
+func TestMaisTempoSoParaQuemUsou(t *testing.T) {
+	server := NovoServer(4)
+	auditoria := auditar(server)
+	ana, deAna := conectarTeste(t, server, "ana")
+	bia, _ := conectarTeste(t, server, "bia")
+	for _, jogador := range []*Jogador{ana, bia} {
+		jogador.ordem = []int{0, 1, 2, 3}
+		jogador.ajudas = novasAjudas(1)
+	}
+	server.jogadores = append(server.jogadores, ana, bia)
+	atual := partidaTeste(server)
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	defer cancelar()
+	resultado := make(chan []models.Resposta, 1)
+	go func() { resultado <- server.ColetarRespostas(ctx, atual, 200*time.Millisecond, perguntaTeste) }()
+	for aberta := false; !aberta; time.Sleep(time.Millisecond) {
+		server.jogadoresMutex.Lock()
+		aberta = server.coleta != nil
+		server.jogadoresMutex.Unlock()
+	}
+
+	pedirAjuda(server, ana, AjudaMaisTempo)
+	if extra := esperarMensagem(t, deAna, "ajuda_usada")["tempo"]; extra != float64(segundosRestantes(TempoExtraAjuda)) {
+		t.Fatalf("tempo extra = %v, esperava %d", extra, segundosRestantes(TempoExtraAjuda))
+	}
+	for esgotou := false; !esgotou; time.Sleep(time.Millisecond) {
+		server.jogadoresMutex.Lock()
+		esgotou = server.coleta != nil && server.coleta.esgotou
+		server.jogadoresMutex.Unlock()
+	}
+
+	// Depois do tempo normal, só ana ainda responde
+	server.receberResposta(bia, []byte(`{"tipo":"resposta","id":1,"opcao":"C"}`), time.Now())
+	if motivo := auditoria.ultimo(); motivo != motivoSemPergunta {
+		t.Fatalf("bia: motivo = %q, esperava %q", motivo, motivoSemPergunta)
+	}
+	server.receberResposta(ana, []byte(`{"tipo":"resposta","id":1,"opcao":"C"}`), time.Now())
+
+	// A coleta acaba com a resposta de ana, sem esperar o resto do tempo extra
+	select {
+	case respostas := <-resultado:
+		if len(respostas) != 1 || respostas[0].JogadorID != ana.ID || !respostas[0].Correta {
+			t.Fatalf("respostas = %+v, esperava só o acerto de ana", respostas)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a coleta não acabou com a resposta de quem pediu mais tempo")
+	}
+}
+
+func TestPularContaComoRespondida(t *testing.T) {
+	server := NovoServer(4)
+	auditoria := auditar(server)
+	ana, deAna := conectarTeste(t, server, "ana")
+	ana.ordem = []int{0, 1, 2, 3}
+	ana.ajudas = novasAjudas(1)
+	server.coleta = coletaTeste()
+
+	pedirAjuda(server, ana, AjudaPular)
+	esperarMensagem(t, deAna, "ajuda_usada")
+	select {
+	case resp := <-ana.respostas:
+		if !resp.Pulou || resp.Alternativa != -1 || resp.Correta {
+			t.Fatalf("resposta = %+v, esperava a pergunta pulada", resp)
+		}
+	default:
+		t.Fatal("o pulo não chegou à coleta")
+	}
+
+	// Quem pulou não responde mais a pergunta
+	server.receberResposta(ana, []byte(`{"tipo":"resposta","id":1,"opcao":"C"}`), time.Now())
+	if motivo := auditoria.ultimo(); motivo != motivoRespostaRepetida {
+		t.Fatalf("motivo = %q, esperava %q", motivo, motivoRespostaRepetida)
+	}
+}
@@ -0,0 +1,177 @@
+// Rodada final com apostas: antes de ver a última pergunta, cada jogador aposta em segredo parte
+// dos seus pontos e ganha a aposta se acertar, ou perde se errar ou não responder
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"triviaMultiplayer/internal/idioma"
+	"triviaMultiplayer/internal/models"
+)
+
+// TempoAposta é quanto tempo os jogadores têm para apostar antes da pergunta final
+const TempoAposta = 15 * time.Second
+
+// rodadaApostas são as apostas abertas, protegidas por jogadoresMutex
+type rodadaApostas struct {
+	maximo  map[*Jogador]int // pontos de cada apostador quando as apostas abriram
+	valores map[*Jogador]int
+	pausada bool
+	chegou  chan struct{} // um aviso por aposta aceita
+}
+
+// rodadaFinal pede as apostas e joga a pergunta final, valendo o que cada um apostou
+func (server *ServerJogo) rodadaFinal(ctx context.Context, atual *partida, pergunta models.Pergunta, config Config, tempoRevelacao time.Duration) error {
+	fmt.Println(idioma.T("RodadaFinal"))
+	apostas, err := server.coletarApostas(ctx, atual)
+	if err != nil {
+		return err
+	}
+	atual.log.Info("apostas coletadas", "apostas", len(apostas))
+
+	server.partidaMutex.Lock()
+	atual.pergunta = atual.total
+	server.partidaMutex.Unlock()
+
+	tempo := time.Duration(config.TempoResposta) * time.Second
+	pergunta.Tempo = segundosRestantes(tempo)
+	pergunta.Final = true
+	server.EnviarPergunta(pergunta, config.EmbaralharPorJogador)
+	respostas := server.ColetarRespostas(ctx, atual, tempo, pergunta)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	server.encerrarPergunta(pergunta, len(respostas), segundosRestantes(tempoRevelacao))
+	for jogador, pontos := range PontuarApostas(respostas, apostas) {
+		server.somarPontos(jogador, pontos)
+	}
+	_, err = server.esperarEtapa(ctx, atual, tempoRevelacao)
+	return err
+}
+
+// PontuarApostas soma a aposta de quem acertou e desconta a de quem errou ou não respondeu.
+// Apostas e respostas são ligadas pelo ID do jogador, já que os nomes podem se repetir.
+func PontuarApostas(respostas []models.Resposta, apostas map[*Jogador]int) map[*Jogador]int {
+	acertou := make(map[int]bool)
+	for _, resp := range respostas {
+		if resp.Correta {
+			acertou[resp.JogadorID] = true
+		}
+	}
+	pontos := make(map[*Jogador]int)
+	for jogador, valor := range apostas {
+		if valor == 0 {
+			continue
+		}
+		if !acertou[jogador.ID] {
+			valor = -valor
+		}
+		pontos[jogador] = valor
+	}
+	return pontos
+}
+
+// coletarApostas manda a cada jogador em jogo quanto ele pode apostar e espera as apostas por
+// TempoAposta, sem contar as pausas, ou até todos apostarem. Quem não aposta fica com zero.
+func (server *ServerJogo) coletarApostas(ctx context.Context, atual *partida) (map[*Jogador]int, error) {
+	jogadores := server.emJogo()
+	rodada := &rodadaApostas{
+		maximo:  make(map[*Jogador]int),
+		valores: make(map[*Jogador]int),
+		chegou:  make(chan struct{}, len(jogadores)),
+	}
+	server.jogadoresMutex.Lock()
+	for _, jogador := range jogadores {
+		maximo := jogador.Pontuacao
+		if maximo < 0 {
+			maximo = 0
+		}
+		rodada.maximo[jogador] = maximo
+		pedido, _ := json.Marshal(models.PedidoAposta{Tipo: "aposta_pedido", Maximo: maximo, Tempo: segundosRestantes(TempoAposta)})
+		server.enviar(jogador, append(pedido, '\n'))
+	}
+	server.apostas = rodada
+	server.jogadoresMutex.Unlock()
+
+	resultado := func() map[*Jogador]int {
+		server.jogadoresMutex.Lock()
+		defer server.jogadoresMutex.Unlock()
+		server.apostas = nil
+		return rodada.valores
+	}
+
+	relogio := server.novoRelogio(atual, TempoAposta)
+	defer relogio.parar()
+	for recebidas := 0; recebidas < len(jogadores); {
+		select {
+		case <-rodada.chegou:
+			recebidas++
+		case <-relogio.timer.C:
+			return resultado(), nil
+		case <-atual.pular:
+			return resultado(), nil
+		case <-relogio.pausar:
+			server.jogadoresMutex.Lock()
+			rodada.pausada = true
+			server.jogadoresMutex.Unlock()
+			if err := relogio.congelar(ctx); err != nil {
+				resultado()
+				return nil, err
+			}
+			server.jogadoresMutex.Lock()
+			rodada.pausada = false
+			server.jogadoresMutex.Unlock()
+		case <-ctx.Done():
+			resultado()
+			return nil, ctx.Err()
+		}
+	}
+	return resultado(), nil
+}
+
+// receberAposta valida a aposta: só uma por jogador, entre zero e os pontos que ele tinha quando
+// as apostas abriram. A aposta aceita é confirmada só para quem apostou.
+func (server *ServerJogo) receberAposta(jogador *Jogador, linha []byte) {
+	var aposta models.Aposta
+	if err := json.Unmarshal(linha, &aposta); err != nil {
+		server.rejeitar(jogador, motivoJSONInvalido, linha)
+		return
+	}
+
+	server.jogadoresMutex.Lock()
+	rodada := server.apostas
+	motivo := ""
+	var maximo int
+	apostador, repetida := false, false
+	if rodada != nil {
+		maximo, apostador = rodada.maximo[jogador]
+		_, repetida = rodada.valores[jogador]
+	}
+	switch {
+	case rodada == nil:
+		motivo = motivoSemAposta
+	case !apostador:
+		motivo = motivoForaDaPartida
+	case rodada.pausada:
+		motivo = motivoPartidaPausada
+	case repetida:
+		motivo = motivoApostaRepetida
+	case aposta.Valor < 0 || aposta.Valor > maximo:
+		motivo = motivoApostaInvalida
+	default:
+		rodada.valores[jogador] = aposta.Valor
+	}
+	server.jogadoresMutex.Unlock()
+
+	if motivo != "" {
+		server.rejeitar(jogador, motivo, linha)
+		return
+	}
+	server.comPartida(jogador.log).Debug("aposta aceita", "valor", aposta.Valor)
+	aceita, _ := json.Marshal(models.Aposta{Tipo: "aposta_aceita", Valor: aposta.Valor})
+	server.enviar(jogador, append(aceita, '\n'))
+	rodada.chegou <- struct{}{} // cabe sempre: uma aposta por apostador
+}
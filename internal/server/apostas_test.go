@@ -0,0 +1,176 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+func TestPontuarApostas(t *testing.T) {
+	ana, bia, caio, davi := &Jogador{ID: 1, Nome: "ana"}, &Jogador{ID: 2, Nome: "bia"}, &Jogador{ID: 3, Nome: "caio"}, &Jogador{ID: 4, Nome: "davi"}
+	outraAna := &Jogador{ID: 5, Nome: "ana"} // mesmo nome, aposta separada
+	respostas := []models.Resposta{
+		{Jogador: "ana", JogadorID: 1, Correta: true},
+		{Jogador: "bia", JogadorID: 2, Correta: false},
+		{Jogador: "ana", JogadorID: 5, Correta: false},
+	}
+	apostas := map[*Jogador]int{ana: 300, bia: 150, caio: 80, davi: 0, outraAna: 50}
+	esperado := map[*Jogador]int{ana: 300, bia: -150, caio: -80, outraAna: -50}
+
+	pontos := PontuarApostas(respostas, apostas)
+	if len(pontos) != len(esperado) {
+		t.Fatalf("PontuarApostas = %v, esperava %v", pontos, esperado)
+	}
+	for jogador, valor := range esperado {
+		if pontos[jogador] != valor {
+			t.Fatalf("PontuarApostas deu %d para %s (ID %d), esperava %d", pontos[jogador], jogador.Nome, jogador.ID, valor)
+		}
+	}
+}
+
+func TestReceberAposta(t *testing.T) {
+	casos := []struct {
+		nome     string
+		preparar func(server *ServerJogo, jogador *Jogador)
+		linha    string
+		motivo   string // vazio quando a aposta é aceita
+	}{
+		{"aceita", nil, `{"tipo":"aposta","valor":200}`, ""},
+		{"tudo o que tem", nil, `{"tipo":"aposta","valor":300}`, ""},
+		{"acima dos pontos", nil, `{"tipo":"aposta","valor":301}`, motivoApostaInvalida},
+		{"negativa", nil, `{"tipo":"aposta","valor":-1}`, motivoApostaInvalida},
+		{"json inválido", nil, `{"tipo":"aposta","valor":`, motivoJSONInvalido},
+		{"segunda aposta", func(server *ServerJogo, jogador *Jogador) { server.apostas.valores[jogador] = 100 },
+			`{"tipo":"aposta","valor":200}`, motivoApostaRepetida},
+		{"depois do prazo", func(server *ServerJogo, jogador *Jogador) { server.apostas = nil },
+			`{"tipo":"aposta","valor":200}`, motivoSemAposta},
+		{"fora das apostas", func(server *ServerJogo, jogador *Jogador) { delete(server.apostas.maximo, jogador) },
+			`{"tipo":"aposta","valor":200}`, motivoForaDaPartida},
+		{"partida pausada", func(server *ServerJogo, jogador *Jogador) { server.apostas.pausada = true },
+			`{"tipo":"aposta","valor":200}`, motivoPartidaPausada},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			server := NovoServer(4)
+			auditoria := auditar(server)
+			jogador, mensagens := conectarTeste(t, server, "ana")
+			rodada := &rodadaApostas{
+				maximo:  map[*Jogador]int{jogador: 300},
+				valores: make(map[*Jogador]int),
+				chegou:  make(chan struct{}, 1),
+			}
+			server.apostas = rodada
+			if caso.preparar != nil {
+				caso.preparar(server, jogador)
+			}
+
+			server.receberAposta(jogador, []byte(caso.linha))
+
+			if motivo := auditoria.ultimo(); motivo != caso.motivo {
+				t.Fatalf("motivo = %q, esperava %q", motivo, caso.motivo)
+			}
+			if caso.motivo != "" {
+				semMensagem(t, mensagens, "aposta_aceita", 100*time.Millisecond)
+				if len(rodada.chegou) != 0 {
+					t.Fatal("aposta rejeitada foi contada")
+				}
+				return
+			}
+			aceita := esperarMensagem(t, mensagens, "aposta_aceita")
+			if valor, _ := aceita["valor"].(float64); int(valor) != rodada.valores[jogador] {
+				t.Fatalf("aposta_aceita com %v, guardada %d", aceita["valor"], rodada.valores[jogador])
+			}
+			if len(rodada.chegou) != 1 {
+				t.Fatal("aposta aceita não foi contada")
+			}
+		})
+	}
+}
+
+// apostasEmAndamento roda coletarApostas em segundo plano e espera as apostas abrirem
+func apostasEmAndamento(t *testing.T, server *ServerJogo) <-chan map[*Jogador]int {
+	t.Helper()
+	atual := partidaTeste(server)
+	ctx, cancelar := context.WithCancel(context.Background())
+	t.Cleanup(cancelar)
+	resultado := make(chan map[*Jogador]int, 1)
+	go func() {
+		apostas, _ := server.coletarApostas(ctx, atual)
+		resultado <- apostas
+	}()
+	for abertas := false; !abertas; time.Sleep(time.Millisecond) {
+		server.jogadoresMutex.Lock()
+		abertas = server.apostas != nil
+		server.jogadoresMutex.Unlock()
+	}
+	return resultado
+}
+
+func TestColetarApostasTerminaQuandoTodosApostam(t *testing.T) {
+	server := NovoServer(4)
+	auditoria := auditar(server)
+	ana, deAna := conectarTeste(t, server, "ana")
+	bia, deBia := conectarTeste(t, server, "bia")
+	ana.Pontuacao, bia.Pontuacao = 300, -20
+	server.jogadores = append(server.jogadores, ana, bia)
+
+	resultado := apostasEmAndamento(t, server)
+	if maximo := esperarMensagem(t, deAna, "aposta_pedido")["maximo"]; maximo != float64(300) {
+		t.Fatalf("ana pode apostar %v, esperava 300", maximo)
+	}
+	if maximo := esperarMensagem(t, deBia, "aposta_pedido")["maximo"]; maximo != float64(0) {
+		t.Fatalf("bia pode apostar %v, esperava 0 com a pontuação negativa", maximo)
+	}
+
+	server.receberAposta(ana, []byte(`{"tipo":"aposta","valor":250}`))
+	server.receberAposta(bia, []byte(`{"tipo":"aposta","valor":0}`))
+
+	var apostas map[*Jogador]int
+	select {
+	case apostas = <-resultado:
+	case <-time.After(time.Second):
+		t.Fatal("coletarApostas não terminou depois de todos apostarem")
+	}
+	if len(apostas) != 2 || apostas[ana] != 250 || apostas[bia] != 0 {
+		t.Fatalf("apostas = %v, esperava ana 250 e bia 0", apostas)
+	}
+
+	// Encerradas as apostas, uma nova chega tarde
+	server.receberAposta(ana, []byte(`{"tipo":"aposta","valor":10}`))
+	if motivo := auditoria.ultimo(); motivo != motivoSemAposta {
+		t.Fatalf("motivo = %q, esperava %q", motivo, motivoSemAposta)
+	}
+}
+
+func TestColetarApostasEncerraNoPrazo(t *testing.T) {
+	server := NovoServer(4)
+	auditoria := auditar(server)
+	ana, _ := conectarTeste(t, server, "ana")
+	bia, _ := conectarTeste(t, server, "bia")
+	ana.Pontuacao, bia.Pontuacao = 300, 200
+	server.jogadores = append(server.jogadores, ana, bia)
+
+	resultado := apostasEmAndamento(t, server)
+	server.receberAposta(ana, []byte(`{"tipo":"aposta","valor":100}`))
+	// Pular encerra as apostas pelo mesmo caminho do fim do TempoAposta
+	if err := server.Pular(); err != nil {
+		t.Fatal(err)
+	}
+
+	var apostas map[*Jogador]int
+	select {
+	case apostas = <-resultado:
+	case <-time.After(time.Second):
+		t.Fatal("coletarApostas não terminou com o pulo")
+	}
+	if len(apostas) != 1 || apostas[ana] != 100 {
+		t.Fatalf("apostas = %v, esperava só a de ana", apostas)
+	}
+
+	server.receberAposta(bia, []byte(`{"tipo":"aposta","valor":50}`))
+	if motivo := auditoria.ultimo(); motivo != motivoSemAposta {
+		t.Fatalf("motivo = %q, esperava %q", motivo, motivoSemAposta)
+	}
+}
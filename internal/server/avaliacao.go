@@ -0,0 +1,84 @@
+// Avaliação das respostas: o mesmo resultado decide o feedback imediato e os pontos
+
+package server
+
+import (
+	"strings"
+	"triviaMultiplayer/internal/models"
+)
+
+// Avaliacao é o que o servidor entendeu da resposta do jogador
+type Avaliacao struct {
+	Alternativa int  // índice canônico escolhido, -1 se a resposta não corresponde a nenhuma alternativa
+	Correta     bool // a alternativa escolhida é a correta
+}
+
+// AvaliarResposta interpreta a opção enviada pelo jogador, na ordem em que ele viu as alternativas
+// e no idioma dele. Aceita a letra ("c", " C ", "C)"), a letra com o texto ("C) Mercúrio") ou só o
+// texto da alternativa, sem diferenciar maiúsculas. Letra e texto que não combinam não valem.
+func AvaliarResposta(pergunta models.Pergunta, idioma string, ordem []int, opcao string) Avaliacao {
+	opcoes := pergunta.Traduzida(idioma).Opcoes
+	posicao := posicaoEscolhida(opcoes, ordem, opcao)
+	if posicao < 0 {
+		return Avaliacao{Alternativa: -1}
+	}
+	alternativa := ordem[posicao]
+	return Avaliacao{Alternativa: alternativa, Correta: alternativa == pergunta.Correta}
+}
+
+// posicaoEscolhida retorna a posição vista pelo jogador que a opção indica, ou -1. Uma letra
+// depois da última alternativa não é letra: pode ser o texto de uma alternativa, como "X".
+func posicaoEscolhida(opcoes []string, ordem []int, opcao string) int {
+	opcao = strings.TrimSpace(opcao)
+	if letra, texto, ok := separarLetra(opcao); ok && int(letra-'A') < len(ordem) {
+		posicao := int(letra - 'A')
+		if ordem[posicao] >= len(opcoes) {
+			return -1
+		}
+		if texto != "" && !strings.EqualFold(texto, strings.TrimSpace(opcoes[ordem[posicao]])) {
+			return -1
+		}
+		return posicao
+	}
+
+	for posicao, indice := range ordem {
+		if indice < len(opcoes) && strings.EqualFold(opcao, strings.TrimSpace(opcoes[indice])) {
+			return posicao
+		}
+	}
+	return -1
+}
+
+// separarLetra reconhece "C", "C)" e "C) texto", devolvendo a letra maiúscula e o texto
+func separarLetra(opcao string) (byte, string, bool) {
+	if opcao == "" {
+		return 0, "", false
+	}
+	letra := strings.ToUpper(opcao[:1])[0]
+	if letra < 'A' || letra > 'Z' {
+		return 0, "", false
+	}
+	switch {
+	case len(opcao) == 1:
+		return letra, "", true
+	case opcao[1] == ')':
+		return letra, strings.TrimSpace(opcao[2:]), true
+	}
+	return 0, "", false
+}
+
+// montarResultado monta o feedback imediato a partir da mesma avaliação usada nos pontos,
+// revelando a alternativa correta na ordem vista pelo jogador
+func montarResultado(pergunta models.Pergunta, idioma string, ordem []int, avaliacao Avaliacao) models.ResultadoResposta {
+	resultado := models.ResultadoResposta{
+		Tipo:         "resultado_resposta",
+		Correta:      avaliacao.Correta,
+		TextoCorreto: pergunta.Traduzida(idioma).Opcoes[pergunta.Correta],
+	}
+	for posicao, indice := range ordem {
+		if indice == pergunta.Correta {
+			resultado.RespostaCorreta = string(rune('A' + posicao))
+		}
+	}
+	return resultado
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// perguntaTeste tem a correta em "Mercúrio" (índice canônico 2) e tradução para inglês
+var perguntaTeste = models.Pergunta{
+	Tipo:    "pergunta",
+	ID:      1,
+	Texto:   "Qual é o planeta mais próximo do Sol?",
+	Opcoes:  []string{"Vênus", "Terra", "Mercúrio", "Marte"},
+	Correta: 2,
+	Traducoes: map[string]models.TraducaoPergunta{
+		"en-US": {Enunciado: "Which planet is closest to the Sun?", Alternativas: []string{"Venus", "Earth", "Mercury", "Mars"}},
+	},
+}
+
+func TestAvaliarResposta(t *testing.T) {
+	naOrdem := []int{0, 1, 2, 3}
+	embaralhada := []int{3, 2, 0, 1} // o jogador vê Marte, Mercúrio, Vênus, Terra
+
+	casos := []struct {
+		nome        string
+		idioma      string
+		ordem       []int
+		opcao       string
+		alternativa int
+		correta     bool
+	}{
+		{"letra correta", "pt-BR", naOrdem, "C", 2, true},
+		{"letra minúscula", "pt-BR", naOrdem, "c", 2, true},
+		{"letra com espaços", "pt-BR", naOrdem, "  C ", 2, true},
+		{"letra com parêntese", "pt-BR", naOrdem, "C)", 2, true},
+		{"letra com o texto", "pt-BR", naOrdem, "C) Mercúrio", 2, true},
+		{"letra com o texto sem espaço", "pt-BR", naOrdem, "C)Mercúrio", 2, true},
+		{"letra com o texto em outra caixa", "pt-BR", naOrdem, "c) MERCÚRIO", 2, true},
+		{"só o texto", "pt-BR", naOrdem, "Mercúrio", 2, true},
+		{"só o texto em minúsculas", "pt-BR", naOrdem, " mercúrio ", 2, true},
+		{"letra errada", "pt-BR", naOrdem, "A", 0, false},
+		{"texto errado", "pt-BR", naOrdem, "Terra", 1, false},
+		{"letra errada com o texto dela", "pt-BR", naOrdem, "D) Marte", 3, false},
+		{"letra e texto que não combinam", "pt-BR", naOrdem, "A) Mercúrio", -1, false},
+		{"letra além das alternativas", "pt-BR", naOrdem, "E", -1, false},
+		{"vazia", "pt-BR", naOrdem, "", -1, false},
+		{"símbolo", "pt-BR", naOrdem, "?", -1, false},
+		{"duas letras", "pt-BR", naOrdem, "AB", -1, false},
+		{"texto que não existe", "pt-BR", naOrdem, "Plutão", -1, false},
+		{"ordem embaralhada, letra correta", "pt-BR", embaralhada, "B", 2, true},
+		{"ordem embaralhada, letra da ordem original", "pt-BR", embaralhada, "C", 0, false},
+		{"ordem embaralhada, letra com o texto", "pt-BR", embaralhada, "B) Mercúrio", 2, true},
+		{"ordem embaralhada, letra original com o texto", "pt-BR", embaralhada, "C) Mercúrio", -1, false},
+		{"ordem embaralhada, só o texto", "pt-BR", embaralhada, "Mercúrio", 2, true},
+		{"tradução, letra", "en-US", embaralhada, "B", 2, true},
+		{"tradução, texto traduzido", "en-US", naOrdem, "Mercury", 2, true},
+		{"tradução, letra com o texto traduzido", "en-US", naOrdem, "C) mercury", 2, true},
+		{"tradução, texto no idioma original", "en-US", naOrdem, "Mercúrio", -1, false},
+		{"idioma sem tradução usa o original", "es-ES", naOrdem, "Mercúrio", 2, true},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			avaliacao := AvaliarResposta(perguntaTeste, caso.idioma, caso.ordem, caso.opcao)
+			if avaliacao.Alternativa != caso.alternativa || avaliacao.Correta != caso.correta {
+				t.Fatalf("AvaliarResposta(%q) = %+v, esperava alternativa %d e correta %v",
+					caso.opcao, avaliacao, caso.alternativa, caso.correta)
+			}
+
+			// O feedback e os pontos saem da mesma avaliação e precisam concordar
+			resultado := montarResultado(perguntaTeste, caso.idioma, caso.ordem, avaliacao)
+			pontos := CalcularPontos([]models.Resposta{{
+				Jogador:     "ana",
+				Opcao:       caso.opcao,
+				Alternativa: avaliacao.Alternativa,
+				Correta:     avaliacao.Correta,
+				Tempo:       time.Now(),
+			}}, Desempate{})
+			if resultado.Correta != (len(pontos) > 0) {
+				t.Fatalf("feedback diz correta=%v, mas os pontos foram %v", resultado.Correta, pontos)
+			}
+		})
+	}
+}
+
+func TestAvaliarRespostaAlternativaDeUmaLetra(t *testing.T) {
+	// Letras depois da última alternativa valem como texto
+	pergunta := models.Pergunta{Opcoes: []string{"X", "Y", "Z", "W"}, Correta: 2}
+	naOrdem := []int{0, 1, 2, 3}
+
+	casos := []struct {
+		opcao       string
+		alternativa int
+	}{
+		{"Z", 2},
+		{"z", 2},
+		{"X", 0},
+		{"C", 2}, // letra dentro das alternativas continua sendo letra
+		{"E", -1},
+	}
+	for _, caso := range casos {
+		if avaliacao := AvaliarResposta(pergunta, "pt-BR", naOrdem, caso.opcao); avaliacao.Alternativa != caso.alternativa {
+			t.Fatalf("AvaliarResposta(%q) = %+v, esperava alternativa %d", caso.opcao, avaliacao, caso.alternativa)
+		}
+	}
+}
+
+func TestMontarResultadoRevelaLetraNaOrdemDoJogador(t *testing.T) {
+	casos := []struct {
+		nome   string
+		idioma string
+		ordem  []int
+		letra  string
+		texto  string
+	}{
+		{"ordem original", "pt-BR", []int{0, 1, 2, 3}, "C", "Mercúrio"},
+		{"ordem embaralhada", "pt-BR", []int{3, 2, 0, 1}, "B", "Mercúrio"},
+		{"correta por último", "pt-BR", []int{0, 1, 3, 2}, "D", "Mercúrio"},
+		{"tradução", "en-US", []int{2, 0, 1, 3}, "A", "Mercury"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			resultado := montarResultado(perguntaTeste, caso.idioma, caso.ordem, Avaliacao{Alternativa: 0})
+			if resultado.Tipo != "resultado_resposta" || resultado.RespostaCorreta != caso.letra || resultado.TextoCorreto != caso.texto {
+				t.Fatalf("montarResultado = %+v, esperava letra %s e texto %s", resultado, caso.letra, caso.texto)
+			}
+		})
+	}
+}
+
+func TestCalcularPontosSoContaAsCorretas(t *testing.T) {
+	inicio := time.Now()
+	respostas := []models.Resposta{
+		{Jogador: "bia", Correta: true, Tempo: inicio.Add(2 * time.Second)},
+		{Jogador: "caio", Correta: false, Tempo: inicio},
+		{Jogador: "ana", Correta: true, Tempo: inicio.Add(time.Second)},
+		{Jogador: "davi", Correta: true, Tempo: inicio.Add(3 * time.Second)},
+	}
+
+	pontos := CalcularPontos(respostas, Desempate{})
+	esperado := []models.Pontuacao{{Jogador: "ana", Pontos: 100}, {Jogador: "bia", Pontos: 50}, {Jogador: "davi", Pontos: 25}}
+	if len(pontos) != len(esperado) {
+		t.Fatalf("CalcularPontos = %v, esperava %v", pontos, esperado)
+	}
+	for i := range esperado {
+		if pontos[i] != esperado[i] {
+			t.Fatalf("CalcularPontos = %v, esperava %v", pontos, esperado)
+		}
+	}
+}
+
+func TestCalcularPontosDesempate(t *testing.T) {
+	inicio := time.Now()
+	em := func(ms int) time.Time { return inicio.Add(time.Duration(ms) * time.Millisecond) }
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	casos := []struct {
+		nome      string
+		desempate Desempate
+		respostas []models.Resposta
+		esperado  map[string]int
+	}{
+		{
+			"sem regras vale a ordem de leitura",
+			Desempate{},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0), RTT: ms(10)}, {Jogador: "bia", Tempo: em(150), RTT: ms(400)}},
+			map[string]int{"ana": 100, "bia": 50},
+		},
+		{
+			"compensação desconta metade do ping",
+			Desempate{CompensarLatencia: true},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0), RTT: ms(10)}, {Jogador: "bia", Tempo: em(150), RTT: ms(400)}},
+			map[string]int{"bia": 100, "ana": 50},
+		},
+		{
+			"compensação tem limite",
+			Desempate{CompensarLatencia: true},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0)}, {Jogador: "bia", Tempo: em(300), RTT: 10 * time.Second}},
+			map[string]int{"ana": 100, "bia": 50},
+		},
+		{
+			"ping desconhecido não compensa",
+			Desempate{CompensarLatencia: true},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0)}, {Jogador: "bia", Tempo: em(10), RTT: -1}},
+			map[string]int{"ana": 100, "bia": 50},
+		},
+		{
+			"empate divide a posição e a próxima pula os empatados",
+			Desempate{Empate: ms(100)},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0)}, {Jogador: "bia", Tempo: em(80)}, {Jogador: "caio", Tempo: em(300)}},
+			map[string]int{"ana": 100, "bia": 100, "caio": 25},
+		},
+		{
+			"o empate conta a partir da primeira do grupo",
+			Desempate{Empate: ms(100)},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0)}, {Jogador: "bia", Tempo: em(80)}, {Jogador: "caio", Tempo: em(160)}},
+			map[string]int{"ana": 100, "bia": 100, "caio": 25},
+		},
+		{
+			"empate depois da compensação",
+			Desempate{CompensarLatencia: true, Empate: ms(50)},
+			[]models.Resposta{{Jogador: "ana", Tempo: em(0)}, {Jogador: "bia", Tempo: em(120), RTT: ms(200)}},
+			map[string]int{"ana": 100, "bia": 100},
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			for i := range caso.respostas {
+				caso.respostas[i].Correta = true
+			}
+			pontos := CalcularPontos(caso.respostas, caso.desempate)
+			if len(pontos) != len(caso.esperado) {
+				t.Fatalf("CalcularPontos = %v, esperava %v", pontos, caso.esperado)
+			}
+			for _, ponto := range pontos {
+				if caso.esperado[ponto.Jogador] != ponto.Pontos {
+					t.Fatalf("CalcularPontos = %v, esperava %v", pontos, caso.esperado)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Batimentos: o servidor manda ping de tempos em tempos e desconecta quem para de responder,
+// mesmo que a conexão não tenha sido fechada (cliente que sumiu da rede sem avisar)
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Valores padrão dos batimentos
+const (
+	IntervaloPingPadrao = 5 * time.Second
+	LimitePingPadrao    = 15 * time.Second
+)
+
+// ConfigurarBatimentos define de quanto em quanto tempo o servidor manda ping e quanto tempo
+// espera, além do intervalo, por qualquer mensagem do jogador antes de desconectá-lo
+func (server *ServerJogo) ConfigurarBatimentos(intervalo, limite time.Duration) {
+	server.intervaloPing = intervalo
+	server.limitePing = limite
+}
+
+// leitor lê tudo o que o jogador envia depois do nome: responde aos pongs e passa as respostas
+// para a validação. Um jogador calado por mais que intervalo+limite é desconectado.
+func (server *ServerJogo) leitor(jogador *Jogador) {
+	defer jogador.encerrar()
+	limite := &limiteMensagens{}
+	for {
+		jogador.Conn.SetReadDeadline(time.Now().Add(server.intervaloPing + server.limitePing))
+		linha, err := jogador.leitor.ReadBytes('\n')
+		recebida := time.Now() // antes de qualquer processamento, para o desempate ser justo
+		if err != nil {
+			var erroRede net.Error
+			if errors.As(err, &erroRede) && erroRede.Timeout() {
+				server.metricas.semResposta.Inc()
+				jogador.log.Info("jogador não responde ao ping, desconectando", "limite", server.limitePing.String())
+			} else {
+				jogador.log.Debug("conexão encerrada", "erro", err)
+			}
+			return
+		}
+		if !server.permitir(jogador, limite, linha) {
+			continue
+		}
+
+		var msg models.Ping
+		if err := json.Unmarshal(linha, &msg); err != nil {
+			server.rejeitar(jogador, motivoJSONInvalido, linha)
+			continue
+		}
+		switch msg.Tipo {
+		case "pong":
+			server.registrarPong(jogador, msg.ID)
+		case "resposta":
+			server.receberResposta(jogador, linha, recebida)
+		case "campainha":
+			server.receberCampainha(jogador, linha, recebida)
+		case "ajuda":
+			server.receberAjuda(jogador, linha, recebida)
+		case "aposta":
+			server.receberAposta(jogador, linha)
+		default:
+			server.rejeitar(jogador, motivoTipoDesconhecido, linha)
+		}
+	}
+}
+
+// batimentos manda um ping a cada intervalo e, fora de partida, a lista da sala de espera
+func (server *ServerJogo) batimentos(jogador *Jogador) {
+	ticker := time.NewTicker(server.intervaloPing)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			server.enviarPing(jogador)
+			if !server.emPartida() {
+				server.enviar(jogador, server.mensagemSalaEspera())
+			}
+		case <-jogador.fim:
+			return
+		case <-server.sair:
+			return
+		}
+	}
+}
+
+// enviarPing guarda quando o ping saiu para medir o tempo de ida e volta no pong
+func (server *ServerJogo) enviarPing(jogador *Jogador) {
+	server.jogadoresMutex.Lock()
+	jogador.pingID++
+	jogador.pingEnviado = time.Now()
+	ping := models.Ping{Tipo: "ping", ID: jogador.pingID}
+	server.jogadoresMutex.Unlock()
+
+	pingBytes, _ := json.Marshal(ping)
+	server.enviar(jogador, append(pingBytes, '\n'))
+}
+
+// registrarPong atualiza o tempo de ida e volta do jogador; pongs de pings antigos são ignorados
+func (server *ServerJogo) registrarPong(jogador *Jogador, id int) {
+	server.jogadoresMutex.Lock()
+	if id != jogador.pingID || jogador.pingEnviado.IsZero() {
+		server.jogadoresMutex.Unlock()
+		return
+	}
+	jogador.rtt = time.Since(jogador.pingEnviado)
+	rtt := jogador.rtt
+	server.jogadoresMutex.Unlock()
+
+	server.metricas.rtt.Observe(rtt.Seconds())
+	jogador.log.Debug("pong recebido", "rtt", rtt.String())
+}
+
+// mensagemSalaEspera monta a lista de jogadores conectados com o ping de cada um
+func (server *ServerJogo) mensagemSalaEspera() []byte {
+	server.jogadoresMutex.Lock()
+	sala := models.SalaEspera{Tipo: "sala_espera", Jogadores: make([]models.JogadorConectado, 0, len(server.jogadores))}
+	for _, jogador := range server.jogadores {
+		ping := -1 // ainda sem pong
+		if jogador.rtt >= 0 {
+			ping = int(jogador.rtt.Milliseconds())
+		}
+		sala.Jogadores = append(sala.Jogadores, models.JogadorConectado{Jogador: jogador.Nome, Ping: ping})
+	}
+	server.jogadoresMutex.Unlock()
+
+	salaBytes, _ := json.Marshal(sala)
+	return append(salaBytes, '\n')
+}
+
+// transmitirSalaEspera avisa todos de quem entrou ou saiu, se não houver partida em andamento
+func (server *ServerJogo) transmitirSalaEspera() {
+	if !server.emPartida() {
+		server.TransmitirMsg(server.mensagemSalaEspera())
+	}
+}
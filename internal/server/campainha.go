@@ -0,0 +1,245 @@
+// Modo campainha: a pergunta aparece para todos, mas só responde quem tocar a campainha primeiro.
+// Quem erra ou deixa a vez passar sem responder cede a vez ao próximo que tocou.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// JanelaCampainha é o tempo de quem está com a vez para responder
+const JanelaCampainha = 5 * time.Second
+
+// modoCampainha pontua como o clássico; como só uma resposta certa é aceita por pergunta,
+// quem acerta leva os 100 pontos
+type modoCampainha struct {
+	modoClassico
+}
+
+func (modo *modoCampainha) Coletar(ctx context.Context, server *ServerJogo, atual *partida, pergunta models.Pergunta, tempo time.Duration) []models.Resposta {
+	pergunta.Campainha = true
+	server.EnviarPergunta(pergunta, modo.config.EmbaralharPorJogador)
+	return server.ColetarCampainha(ctx, atual, tempo, pergunta, modo.config.Desempate())
+}
+
+// ColetarCampainha recebe os toques durante o tempo da pergunta e dá a vez a um jogador de cada vez,
+// na ordem dos toques com as mesmas regras de desempate das respostas. Com compensação de latência,
+// a primeira vez espera CompensacaoMaxima para um toque de quem está longe poder passar na frente.
+// A coleta acaba com o primeiro acerto, quando todos já tentaram ou quando o tempo dos toques
+// acaba sem ninguém com a vez.
+func (server *ServerJogo) ColetarCampainha(ctx context.Context, partidaAtual *partida, tempo time.Duration, pergunta models.Pergunta, desempate Desempate) []models.Resposta {
+	jogadores := server.emJogo()
+	var respostas []models.Resposta
+	inicio := time.Now()
+	relogio := server.novoRelogio(partidaAtual, tempo)
+	defer relogio.parar()
+	atual := &coleta{
+		pergunta:  pergunta,
+		limite:    relogio.fim,
+		respostas: make(chan models.Resposta, len(jogadores)),
+		entrou:    make(chan chegada, server.maxJogadores), // uma chegada por jogador conectado
+		esgotado:  make(chan struct{}),
+		fim:       make(chan struct{}),
+		campainha: true,
+		tocando:   true,
+		toques:    make(chan models.Resposta, server.maxJogadores), // um toque por jogador conectado
+	}
+	server.jogadoresMutex.Lock()
+	server.coleta = atual
+	server.jogadoresMutex.Unlock()
+	defer func() {
+		server.jogadoresMutex.Lock()
+		server.coleta = nil
+		server.jogadoresMutex.Unlock()
+		close(atual.fim)
+	}()
+
+	for _, jogador := range jogadores {
+		go server.lerResposta(jogador, atual)
+	}
+
+	espera := time.Duration(0)
+	if desempate.CompensarLatencia {
+		espera = CompensacaoMaxima
+	}
+
+	var fila []models.Resposta // toques esperando a vez
+	var decisao *time.Timer    // espera a compensação antes de dar a primeira vez
+	var decidir <-chan time.Time
+	var ateDecisao time.Time
+	var janela *time.Timer
+	var fimJanela <-chan time.Time
+	var ateJanela time.Time
+	vez := 0 // ID de Jogador; os nomes podem se repetir
+	tentaram, esperadas := 0, len(jogadores)
+	tocando := true
+
+	definirVez := func(id int) {
+		vez = id
+		server.jogadoresMutex.Lock()
+		atual.vez = id
+		server.jogadoresMutex.Unlock()
+	}
+	// proxima passa a vez ao primeiro da fila, ou a ninguém se a fila estiver vazia
+	proxima := func() {
+		if janela != nil {
+			janela.Stop()
+			janela, fimJanela = nil, nil
+		}
+		definirVez(0)
+		if len(fila) == 0 {
+			return
+		}
+		sort.SliceStable(fila, func(i, j int) bool {
+			return momentoResposta(fila[i], desempate).Before(momentoResposta(fila[j], desempate))
+		})
+		toque := fila[0]
+		fila = fila[1:]
+		tentaram++
+		definirVez(toque.JogadorID)
+		janela = time.NewTimer(JanelaCampainha)
+		fimJanela = janela.C
+		ateJanela = time.Now().Add(JanelaCampainha)
+		server.avisarVez(pergunta.ID, toque.Jogador, JanelaCampainha)
+	}
+	defer func() {
+		if janela != nil {
+			janela.Stop()
+		}
+		if decisao != nil {
+			decisao.Stop()
+		}
+	}()
+
+	for vez != 0 || len(fila) > 0 || decidir != nil || (tocando && tentaram < esperadas) {
+		select {
+		case toque := <-atual.toques:
+			fila = append(fila, toque)
+			if vez == 0 && decidir == nil {
+				decisao = time.NewTimer(espera)
+				decidir = decisao.C
+				ateDecisao = time.Now().Add(espera)
+			}
+		case <-decidir:
+			decisao, decidir = nil, nil
+			if vez == 0 {
+				proxima()
+			}
+		case resp := <-atual.respostas:
+			respostas = append(respostas, resp)
+			server.metricas.respostas.WithLabelValues(strconv.Itoa(pergunta.ID)).Inc()
+			server.metricas.latenciaResposta.Observe(resp.Tempo.Sub(inicio).Seconds())
+			if resp.Correta {
+				return respostas
+			}
+			proxima()
+		case <-fimJanela:
+			proxima() // A vez passou sem resposta
+		case nova := <-atual.entrou:
+			if server.entregarAtrasado(atual, nova) {
+				esperadas++
+			}
+		case <-relogio.timer.C:
+			// Acabou o tempo para tocar; quem já tocou ainda tem a vez
+			tocando = false
+			server.jogadoresMutex.Lock()
+			atual.tocando = false
+			server.jogadoresMutex.Unlock()
+		case <-partidaAtual.pular:
+			return respostas
+		case <-relogio.pausar:
+			// A vez e a espera da compensação também param durante a pausa
+			restanteJanela, restanteDecisao := time.Duration(0), time.Duration(0)
+			if janela != nil {
+				janela.Stop()
+				restanteJanela = time.Until(ateJanela)
+			}
+			if decisao != nil {
+				decisao.Stop()
+				restanteDecisao = time.Until(ateDecisao)
+			}
+			server.jogadoresMutex.Lock()
+			atual.congelado = relogio.restante()
+			atual.pausada = true
+			server.jogadoresMutex.Unlock()
+			if relogio.congelar(ctx) != nil {
+				return respostas
+			}
+			server.jogadoresMutex.Lock()
+			atual.pausada = false
+			atual.limite = relogio.fim
+			server.jogadoresMutex.Unlock()
+			if janela != nil {
+				janela = time.NewTimer(restanteJanela)
+				fimJanela = janela.C
+				ateJanela = time.Now().Add(restanteJanela)
+			}
+			if decisao != nil {
+				decisao = time.NewTimer(restanteDecisao)
+				decidir = decisao.C
+				ateDecisao = time.Now().Add(restanteDecisao)
+			}
+		case <-ctx.Done():
+			return respostas
+		}
+	}
+	return respostas
+}
+
+// receberCampainha valida o toque assim que o leitor o recebe e o passa para a coleta com o momento
+// da leitura, como as respostas. Cada jogador toca uma vez por pergunta.
+func (server *ServerJogo) receberCampainha(jogador *Jogador, linha []byte, recebida time.Time) {
+	var toque models.Campainha
+	if err := json.Unmarshal(linha, &toque); err != nil {
+		server.rejeitar(jogador, motivoJSONInvalido, linha)
+		return
+	}
+
+	server.jogadoresMutex.Lock()
+	atual := server.coleta
+	motivo := ""
+	var rtt time.Duration
+	switch {
+	case atual == nil:
+		motivo = motivoSemPergunta
+	case !atual.campainha || !atual.tocando:
+		motivo = motivoSemCampainha
+	case jogador.aguardando, jogador.eliminado:
+		motivo = motivoForaDaPartida
+	case atual.pausada:
+		motivo = motivoPartidaPausada
+	case toque.ID != atual.pergunta.ID:
+		motivo = motivoPerguntaErrada
+	case jogador.tocou == atual:
+		motivo = motivoToqueRepetido
+	default:
+		jogador.tocou = atual
+		if jogador.rtt > 0 {
+			rtt = jogador.rtt
+		}
+	}
+	server.jogadoresMutex.Unlock()
+
+	if motivo != "" {
+		server.rejeitar(jogador, motivo, linha)
+		return
+	}
+	resp := models.Resposta{Jogador: jogador.Nome, JogadorID: jogador.ID, ID: toque.ID, Tempo: recebida, RTT: rtt}
+	select {
+	case atual.toques <- resp:
+		server.comPartida(jogador.log).Debug("campainha", "pergunta", toque.ID)
+	default:
+		server.rejeitar(jogador, motivoToqueRepetido, linha)
+	}
+}
+
+// avisarVez conta a todos quem está com a vez e por quanto tempo
+func (server *ServerJogo) avisarVez(id int, jogador string, tempo time.Duration) {
+	msg, _ := json.Marshal(models.Vez{Tipo: "vez", ID: id, Jogador: jogador, Tempo: segundosRestantes(tempo)})
+	server.transmitirPartida(append(msg, '\n'))
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// toqueTeste é o toque da campainha em perguntaTeste
+var toqueTeste = []byte(`{"tipo":"campainha","id":1}`)
+
+// campainhaEmAndamento roda ColetarCampainha em segundo plano com os jogadores já no servidor e
+// espera a coleta abrir
+func campainhaEmAndamento(t *testing.T, server *ServerJogo, desempate Desempate) <-chan []models.Resposta {
+	t.Helper()
+	atual := partidaTeste(server)
+	ctx, cancelar := context.WithCancel(context.Background())
+	t.Cleanup(cancelar)
+	resultado := make(chan []models.Resposta, 1)
+	go func() {
+		resultado <- server.ColetarCampainha(ctx, atual, 30*time.Second, perguntaTeste, desempate)
+	}()
+	for aberta := false; !aberta; time.Sleep(time.Millisecond) {
+		server.jogadoresMutex.Lock()
+		aberta = server.coleta != nil
+		server.jogadoresMutex.Unlock()
+	}
+	return resultado
+}
+
+// esperarVez espera o aviso de vez e confere com quem ela ficou
+func esperarVez(t *testing.T, mensagens <-chan map[string]any, jogador string) {
+	t.Helper()
+	if vez := esperarMensagem(t, mensagens, "vez"); vez["jogador"] != jogador {
+		t.Fatalf("vez de %v, esperava %s", vez["jogador"], jogador)
+	}
+}
+
+func TestCampainhaOrdemDosToques(t *testing.T) {
+	casos := []struct {
+		nome      string
+		compensar bool
+		primeiro  string
+	}{
+		{"sem compensação vale a chegada", false, "ana"},
+		// bia tocou 100ms depois, mas metade do RTT dela (200ms) a põe na frente
+		{"com compensação vale o toque", true, "bia"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			server := NovoServer(4)
+			ana, deAna := conectarTeste(t, server, "ana")
+			bia, _ := conectarTeste(t, server, "bia")
+			bia.rtt = 400 * time.Millisecond
+			server.jogadores = append(server.jogadores, ana, bia)
+			campainhaEmAndamento(t, server, Desempate{CompensarLatencia: caso.compensar})
+
+			agora := time.Now()
+			server.receberCampainha(ana, toqueTeste, agora)
+			server.receberCampainha(bia, toqueTeste, agora.Add(100*time.Millisecond))
+
+			esperarVez(t, deAna, caso.primeiro)
+		})
+	}
+}
+
+func TestCampainhaRespostaErradaPassaAVez(t *testing.T) {
+	server := NovoServer(4)
+	ana, deAna := conectarTeste(t, server, "ana")
+	bia, _ := conectarTeste(t, server, "bia")
+	ana.ordem, bia.ordem = []int{0, 1, 2, 3}, []int{0, 1, 2, 3} // viram as alternativas sem embaralhar
+	server.jogadores = append(server.jogadores, ana, bia)
+	resultado := campainhaEmAndamento(t, server, Desempate{})
+
+	agora := time.Now()
+	server.receberCampainha(ana, toqueTeste, agora)
+	server.receberCampainha(bia, toqueTeste, agora.Add(time.Millisecond))
+	esperarVez(t, deAna, "ana")
+
+	server.receberResposta(ana, []byte(`{"tipo":"resposta","id":1,"opcao":"A"}`), time.Now())
+	esperarVez(t, deAna, "bia")
+	server.receberResposta(bia, []byte(`{"tipo":"resposta","id":1,"opcao":"C"}`), time.Now())
+
+	select {
+	case respostas := <-resultado:
+		if len(respostas) != 2 || respostas[0].JogadorID != ana.ID || respostas[0].Correta ||
+			respostas[1].JogadorID != bia.ID || !respostas[1].Correta {
+			t.Fatalf("respostas = %+v, esperava o erro de ana e o acerto de bia", respostas)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a coleta não acabou com o acerto")
+	}
+}
+
+func TestCampainhaVezPassaNoTempoEPausaCongela(t *testing.T) {
+	if testing.Short() {
+		t.Skip("espera a janela inteira da campainha")
+	}
+	server := NovoServer(4)
+	ana, deAna := conectarTeste(t, server, "ana")
+	bia, _ := conectarTeste(t, server, "bia")
+	server.jogadores = append(server.jogadores, ana, bia)
+	campainhaEmAndamento(t, server, Desempate{})
+
+	agora := time.Now()
+	server.receberCampainha(ana, toqueTeste, agora)
+	server.receberCampainha(bia, toqueTeste, agora.Add(time.Millisecond))
+	esperarVez(t, deAna, "ana")
+	inicio := time.Now()
+
+	// Pausa perto do fim da vez de ana e fica parada além do fim da janela
+	const sobra = 500 * time.Millisecond
+	time.Sleep(JanelaCampainha - sobra - time.Since(inicio))
+	if err := server.Pausar(); err != nil {
+		t.Fatal(err)
+	}
+	semMensagem(t, deAna, "vez", time.Second)
+	if err := server.Retomar(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Na volta, ana ainda tem o que faltava da vez, e só depois ela passa a bia
+	retomada := time.Now()
+	esperarVez(t, deAna, "bia")
+	if espera := time.Since(retomada); espera < sobra/2 || espera > 2*sobra {
+		t.Fatalf("a vez passou %v depois da retomada, esperava cerca de %v", espera, sobra)
+	}
+}
+
+func TestCampainhaPausaCongelaACompensacao(t *testing.T) {
+	server := NovoServer(4)
+	ana, deAna := conectarTeste(t, server, "ana")
+	server.jogadores = append(server.jogadores, ana)
+	campainhaEmAndamento(t, server, Desempate{CompensarLatencia: true})
+
+	// A primeira vez espera CompensacaoMaxima; a pausa entra antes disso
+	server.receberCampainha(ana, toqueTeste, time.Now())
+	time.Sleep(CompensacaoMaxima / 5)
+	if err := server.Pausar(); err != nil {
+		t.Fatal(err)
+	}
+	esperarMensagem(t, deAna, "pausa")
+	semMensagem(t, deAna, "vez", 2*CompensacaoMaxima)
+	if err := server.Retomar(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Na volta, a espera continua de onde parou em vez de dar a vez na hora
+	retomada := time.Now()
+	esperarVez(t, deAna, "ana")
+	if espera := time.Since(retomada); espera < CompensacaoMaxima/2 {
+		t.Fatalf("a vez saiu %v depois da retomada, esperava o resto da compensação", espera)
+	}
+}
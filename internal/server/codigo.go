@@ -0,0 +1,53 @@
+// Códigos de entrada: um código curto por sala, anunciado na rede local e mostrado com QR code
+
+package server
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Letras do código, sem as que se confundem ao ditar ou ler (0/O, 1/I/L)
+const alfabetoCodigo = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// Tamanho do código de entrada
+const tamanhoCodigo = 6
+
+// gerarCodigo sorteia um código de entrada
+func gerarCodigo() string {
+	codigo := make([]byte, tamanhoCodigo)
+	for i := range codigo {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alfabetoCodigo))))
+		if err != nil {
+			panic(err)
+		}
+		codigo[i] = alfabetoCodigo[n.Int64()]
+	}
+	return string(codigo)
+}
+
+// DefinirSala dá nome à sala do servidor
+func (server *ServerJogo) DefinirSala(nome string) {
+	server.sala = nome
+}
+
+// Sala retorna o nome da sala
+func (server *ServerJogo) Sala() string {
+	return server.sala
+}
+
+// Codigo retorna o código de entrada da sala
+func (server *ServerJogo) Codigo() string {
+	return server.codigo
+}
+
+// QRCode desenha o conteúdo como QR code em texto, para mostrar no console
+func QRCode(conteudo string) (string, error) {
+	qr, err := qrcode.New(conteudo, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	return qr.ToSmallString(false), nil
+}
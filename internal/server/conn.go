@@ -2,52 +2,148 @@ package server
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"triviaMultiplayer/internal/idioma"
 	"triviaMultiplayer/internal/models"
+	"triviaMultiplayer/internal/perguntas"
 )
 
 // Jogador representa um jogador conectado
 type Jogador struct {
+	ID        int // identifica o jogador nos registros, mesmo com nomes repetidos
 	Nome      string
+	Idioma    string
+	IP        string
 	Conn      net.Conn
 	Pontuacao int
+	ordem     []int // ordem[posição vista pelo jogador] = índice canônico da alternativa
+	log       *slog.Logger
+
+	aguardando bool // chegou no meio da partida e espera a próxima, protegido por jogadoresMutex
+	eliminado  bool // saiu da partida pelas regras do modo e só assiste, protegido por jogadoresMutex
+
+	vidas           int // vidas que restam nos modos com vidas, protegido por jogadoresMutex
+	ordemEliminacao int // 1 para o primeiro eliminado da partida, protegido por jogadoresMutex
+
+	fila        chan mensagem // mensagens esperando a goroutine escritora
+	fim         chan struct{} // fechado para desconectar o jogador (expulsão, lentidão, erro)
+	fimOnce     *sync.Once
+	escritorFim chan struct{} // fechado quando a escritora termina de esvaziar a fila
+
+	leitor      *bufio.Reader
+	respostas   chan models.Resposta // resposta validada pelo leitor, esperando a coleta
+	respondida  *coleta              // última pergunta respondida, protegida por jogadoresMutex
+	tocou       *coleta              // última pergunta em que tocou a campainha, protegida por jogadoresMutex
+	ajudas      map[string]int       // saldo de cada ajuda na partida, protegido por jogadoresMutex
+	perguntaEm  time.Time            // quando a pergunta atual foi escrita na conexão, zero enquanto está na fila; protegido por jogadoresMutex
+	pingID      int                  // último ping enviado, protegido por jogadoresMutex
+	pingEnviado time.Time            // protegido por jogadoresMutex
+	rtt         time.Duration        // tempo de ida e volta do último pong, -1 sem medida; protegido por jogadoresMutex
 }
 
 // ServerJogo gerencia as conexões e estado do jogo
 type ServerJogo struct {
-	jogadores      []*Jogador
-	jogadoresMutex *sync.Mutex
-	semaforo       chan struct{}
-	maxJogadores   int
-	sair           chan struct{}
-	listener       net.Listener
+	jogadores           []*Jogador
+	jogadoresMutex      *sync.Mutex
+	semaforo            chan struct{}
+	maxJogadores        int
+	sair                chan struct{}
+	listener            net.Listener
+	tlsConfig           *tls.Config
+	mux                 *http.ServeMux
+	httpServer          *http.Server
+	descoberta          *net.UDPConn
+	sala                string
+	codigo              string
+	banidos             map[string]bool // IPs que não podem entrar, protegido por jogadoresMutex
+	banco               *perguntas.Banco
+	config              Config
+	partida             *partida
+	partidaMutex        *sync.Mutex
+	metricas            *metricas
+	log                 *slog.Logger
+	proximoID           int            // próximo ID de jogador, protegido por jogadoresMutex
+	proximaPartida      int            // último ID de partida usado, protegido por partidaMutex
+	encerrando          bool           // não aceita mais jogadores, protegido por jogadoresMutex
+	coleta              *coleta        // pergunta aberta para respostas, protegida por jogadoresMutex
+	apostas             *rodadaApostas // apostas abertas da rodada final, protegidas por jogadoresMutex
+	pararOnce           *sync.Once
+	tamanhoFila         int
+	prazoEscrita        time.Duration
+	intervaloPing       time.Duration
+	limitePing          time.Duration
+	auditoria           *slog.Logger
+	reacaoMinima        time.Duration
+	mensagensPorSegundo int
 }
 
 // NovoServer cria uma nova instância do servidor
 func NovoServer(maxJogadores int) *ServerJogo {
 	return &ServerJogo{
-		jogadores:      make([]*Jogador, 0),
-		jogadoresMutex: &sync.Mutex{},
-		semaforo:       make(chan struct{}, maxJogadores),
-		maxJogadores:   maxJogadores,
-		sair:           make(chan struct{}),
+		jogadores:           make([]*Jogador, 0),
+		jogadoresMutex:      &sync.Mutex{},
+		semaforo:            make(chan struct{}, maxJogadores),
+		maxJogadores:        maxJogadores,
+		sair:                make(chan struct{}),
+		mux:                 http.NewServeMux(),
+		sala:                "Principal",
+		codigo:              gerarCodigo(),
+		banidos:             make(map[string]bool),
+		config:              ConfigPadrao(),
+		partidaMutex:        &sync.Mutex{},
+		metricas:            novasMetricas(),
+		log:                 slog.Default(),
+		proximoID:           1,
+		pararOnce:           &sync.Once{},
+		tamanhoFila:         TamanhoFilaPadrao,
+		prazoEscrita:        PrazoEscritaPadrao,
+		intervaloPing:       IntervaloPingPadrao,
+		limitePing:          LimitePingPadrao,
+		reacaoMinima:        ReacaoMinimaPadrao,
+		mensagensPorSegundo: MensagensPorSegundoPadrao,
+	}
+}
+
+// UsarLog troca o logger dos registros estruturados do servidor
+func (server *ServerJogo) UsarLog(logger *slog.Logger) {
+	server.log = logger
+}
+
+// UsarTLS faz o servidor aceitar apenas conexões TLS com o certificado informado
+func (server *ServerJogo) UsarTLS(certificado tls.Certificate) {
+	server.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{certificado},
+		MinVersion:   tls.VersionTLS12,
 	}
 }
 
 // Inicia o servidor na porta especificada
 func (server *ServerJogo) IniciarServer(porta string) error {
-	listener, err := net.Listen("tcp", porta)
+	var listener net.Listener
+	var err error
+	if server.tlsConfig != nil {
+		listener, err = tls.Listen("tcp", porta, server.tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", porta)
+	}
 	if err != nil {
 		return fmt.Errorf("erro ao iniciar servidor: %w", err)
 	}
 
 	server.listener = listener
-	fmt.Printf("Servidor ouvindo em %s\n", porta)
+	fmt.Println(idioma.T("ServidorOuvindo", idioma.Dados{"Endereco": porta}))
 
 	// Goroutine para aceitar conexões
 	go server.aceitarConnect()
@@ -64,29 +160,44 @@ func (server *ServerJogo) aceitarConnect() {
 		default:
 			conn, err := server.listener.Accept()
 			if err != nil {
-				if strings.Contains(err.Error(), "conexão fechada") {
-					return
+				if errors.Is(err, net.ErrClosed) {
+					return // listener fechado em Encerrar ou Parar
 				}
-				fmt.Println("Erro ao aceitar conexão:", err)
+				server.log.Warn("erro ao aceitar conexão", "erro", err)
 				continue
 			}
+			server.log.Debug("conexão aceita", "remoto", conn.RemoteAddr().String())
 
-			// Controla o número máximo de jogadores
-			select {
-			case server.semaforo <- struct{}{}:
-				go server.controlaCliente(conn)
-			default:
-				// Servidor lotado
-				conn.Write([]byte("{\"tipo\":\"servidor_lotado\"}\n"))
-				conn.Close()
-			}
+			go server.admitir(conn, hostDe(conn.RemoteAddr().String()))
 		}
 	}
 }
 
+// admitir recusa IPs banidos, controla o número máximo de jogadores e cuida do cliente até ele sair.
+// É usado tanto pelas conexões TCP quanto pelas WebSocket.
+func (server *ServerJogo) admitir(conn net.Conn, ip string) {
+	if server.estaEncerrando() {
+		server.recusar(conn, ip, "servidor_encerrando")
+		return
+	}
+	if server.banido(ip) {
+		server.recusar(conn, ip, "banido")
+		return
+	}
+
+	select {
+	case server.semaforo <- struct{}{}:
+		server.metricas.conexoesAceitas.Inc()
+		server.controlaCliente(conn, ip)
+	default:
+		// Servidor lotado
+		server.recusar(conn, ip, "servidor_lotado")
+	}
+}
+
 // Gerencia a conexão de um cliente
-func (server *ServerJogo) controlaCliente(conn net.Conn) {
-	jogador := &Jogador{Conn: conn}
+func (server *ServerJogo) controlaCliente(conn net.Conn, ip string) {
+	jogador := server.novoJogador(conn, ip)
 
 	defer conn.Close()
 	defer func() { <-server.semaforo }()
@@ -94,42 +205,95 @@ func (server *ServerJogo) controlaCliente(conn net.Conn) {
 
 	// Solicita o nome do jogador
 	if !server.pedirNome(jogador) {
+		jogador.log.Debug("conexão encerrada antes do nome")
 		return
 	}
+	jogador.log = jogador.log.With("nome", jogador.Nome)
+	jogador.log.Info("jogador entrou", "idioma", jogador.Idioma)
 
-	// Adiciona o jogador à lista
+	// Adiciona o jogador à lista; daqui em diante tudo o que ele recebe passa pela fila
 	server.addJogador(jogador)
-
-	// Aguarda o fim do jogo
-	<-server.sair
+	go server.escritor(jogador)
+	go server.leitor(jogador)
+	go server.batimentos(jogador)
+
+	// Aguarda o fim do jogo ou o fim da conexão (expulsão, jogador lento, sem pong ou erro de escrita)
+	select {
+	case <-server.sair:
+	case <-jogador.fim:
+	}
+	jogador.encerrar()
+	<-jogador.escritorFim // deixa a fila esvaziar antes de fechar a conexão
 }
 
-// pedirNome solicita e obtém o nome do jogador
+// PrazoNome é quanto tempo um cliente conectado tem para mandar o nome; o cliente de terminal
+// pede o nome à pessoa depois de conectar
+const PrazoNome = time.Minute
+
+// pedirNome solicita e obtém o nome do jogador. Quem não manda o nome dentro de PrazoNome é
+// desconectado, para não segurar a vaga do semáforo.
 func (server *ServerJogo) pedirNome(jogador *Jogador) bool {
+	jogador.Conn.SetWriteDeadline(time.Now().Add(server.prazoEscrita))
 	_, err := jogador.Conn.Write([]byte("{\"tipo\":\"nome_requisicao\"}\n"))
 	if err != nil {
 		return false
 	}
 
-	nome, err := bufio.NewReader(jogador.Conn).ReadString('\n')
+	jogador.Conn.SetReadDeadline(time.Now().Add(PrazoNome))
+	linha, err := jogador.leitor.ReadBytes('\n')
+	jogador.Conn.SetReadDeadline(time.Time{})
 	if err != nil {
 		return false
 	}
 
-	jogador.Nome = strings.TrimSpace(nome)
+	// O cliente envia {"tipo":"entrar","nome":...,"idioma":...}; clientes antigos enviam só o nome
+	var entrar models.Entrar
+	if err := json.Unmarshal(linha, &entrar); err != nil || entrar.Tipo != "entrar" {
+		entrar = models.Entrar{}
+		if err := json.Unmarshal(linha, &entrar.Nome); err != nil {
+			entrar.Nome = string(linha)
+		}
+	}
+
+	jogador.Nome = strings.TrimSpace(entrar.Nome)
+	jogador.Idioma = idioma.Escolher(entrar.Idioma)
 	jogador.Pontuacao = 0
 	return true
 }
 
-// Adiciona um jogador à lista de forma thread-safe
+// novoIDJogador reserva o próximo ID de jogador
+func (server *ServerJogo) novoIDJogador() int {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	id := server.proximoID
+	server.proximoID++
+	return id
+}
+
+// Adiciona um jogador à lista de forma thread-safe. Quem chega no meio de uma partida
+// segue a política de entrada tardia da sala.
 func (server *ServerJogo) addJogador(jogador *Jogador) {
+	server.partidaMutex.Lock()
+	emPartida := server.partida != nil
+	config := server.config
 	server.jogadoresMutex.Lock()
+	if emPartida {
+		server.posicionarAtrasado(jogador)
+	}
 	server.jogadores = append(server.jogadores, jogador)
 	numPlayers := len(server.jogadores)
+	atual := server.coleta
 	server.jogadoresMutex.Unlock()
+	server.partidaMutex.Unlock()
+	server.metricas.jogadoresAtivos.Set(float64(numPlayers))
 
-	fmt.Printf("%s conectou-se. (%d/%d jogadores conectados)\n",
-		jogador.Nome, numPlayers, server.maxJogadores)
+	fmt.Println(idioma.T("JogadorConectou", idioma.Dados{"Nome": jogador.Nome, "Conectados": numPlayers, "Maximo": server.maxJogadores}))
+	if emPartida {
+		server.avisarAtrasado(jogador, server.Estado(), config, atual)
+	} else {
+		server.transmitirSalaEspera()
+	}
 }
 
 // Remove um jogador da lista de forma thread-safe
@@ -143,23 +307,22 @@ func (server *ServerJogo) removerJogador(jogador *Jogador) {
 	}
 	numJogadores := len(server.jogadores)
 	server.jogadoresMutex.Unlock()
+	server.metricas.jogadoresAtivos.Set(float64(numJogadores))
 
 	if jogador.Nome != "" {
-		fmt.Printf("%s desconectou-se. (%d/%d jogadores restantes)\n",
-			jogador.Nome, numJogadores, server.maxJogadores)
+		server.comPartida(jogador.log).Info("jogador saiu", "conectados", numJogadores)
+		fmt.Println(idioma.T("JogadorDesconectou", idioma.Dados{"Nome": jogador.Nome, "Conectados": numJogadores, "Maximo": server.maxJogadores}))
+		server.transmitirSalaEspera()
 	} else {
-		fmt.Printf("Um jogador desconectou-se antes de se identificar. (%d/%d jogadores restantes)\n",
-			numJogadores, server.maxJogadores)
+		fmt.Println(idioma.T("JogadorAnonimoDesconectou", idioma.Dados{"Conectados": numJogadores, "Maximo": server.maxJogadores}))
 	}
 }
 
-// Envia uma mensagem para todos os jogadores conectados
+// Envia uma mensagem para todos os jogadores conectados. Só coloca a mensagem nas filas,
+// então não espera nenhum jogador.
 func (server *ServerJogo) TransmitirMsg(msg []byte) {
-	server.jogadoresMutex.Lock()
-	defer server.jogadoresMutex.Unlock()
-
-	for _, jogador := range server.jogadores {
-		jogador.Conn.Write(msg)
+	for _, jogador := range server.RetornarJogadores() {
+		server.enviar(jogador, msg)
 	}
 }
 
@@ -193,68 +356,292 @@ func (server *ServerJogo) AtualizarPontos(nomeJogador string, pontos int) {
 	}
 }
 
-// Coleta respostas e dá feddback
-func (server *ServerJogo) ColetarRespostas(tempo_duracao time.Duration, opcaoCorreta string) []models.Resposta {
-	jogadores := server.RetornarJogadores()
+// somarPontos soma os pontos a um jogador específico, mesmo que outro tenha o mesmo nome
+func (server *ServerJogo) somarPontos(jogador *Jogador, pontos int) {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+	jogador.Pontuacao += pontos
+}
+
+// EnviarPergunta embaralha as alternativas e envia a pergunta a todos os jogadores.
+// Com porJogador, cada jogador recebe uma ordem diferente; senão a ordem é a mesma para todos.
+func (server *ServerJogo) EnviarPergunta(pergunta models.Pergunta, porJogador bool) {
+	server.jogadoresMutex.Lock()
+	var nomes []string
+	ordem := rand.Perm(len(pergunta.Opcoes))
+	for _, jogador := range server.jogadores {
+		if jogador.aguardando {
+			continue
+		}
+		nomes = append(nomes, jogador.Nome)
+		if porJogador {
+			ordem = rand.Perm(len(pergunta.Opcoes))
+		}
+		enviada := pergunta
+		enviada.Espectador = jogador.eliminado // quem foi eliminado assiste sem responder
+		if !enviada.Espectador && !enviada.Campainha && !enviada.Final {
+			enviada.Ajudas = jogador.ajudas
+		}
+		server.enviarPergunta(jogador, mensagemPergunta(jogador, enviada, ordem))
+	}
+	server.jogadoresMutex.Unlock()
+
+	server.registrarVista(pergunta, nomes)
+}
+
+// registrarVista marca no histórico do banco, na memória, que os jogadores viram a pergunta.
+// O histórico vai para o disco no fim da partida.
+func (server *ServerJogo) registrarVista(pergunta models.Pergunta, nomes []string) {
+	if server.banco != nil {
+		server.banco.RegistrarVista(pergunta, nomes)
+	}
+}
+
+// mensagemPergunta guarda a ordem das alternativas do jogador e monta a pergunta no idioma dele.
+// Deve ser chamada com jogadoresMutex travado.
+func mensagemPergunta(jogador *Jogador, pergunta models.Pergunta, ordem []int) []byte {
+	jogador.ordem = ordem
+	jogador.perguntaEm = time.Time{} // marcado pela escritora quando a pergunta sai
+
+	traduzida := pergunta.Traduzida(jogador.Idioma)
+	msg := traduzida
+	msg.Opcoes = make([]string, len(ordem))
+	for posicao, indice := range ordem {
+		msg.Opcoes[posicao] = traduzida.Opcoes[indice]
+	}
+	perguntaBytes, _ := json.Marshal(msg)
+	return append(perguntaBytes, '\n')
+}
+
+// coleta é a pergunta aberta para respostas; quem entra no meio da pergunta também pode responder
+type coleta struct {
+	pergunta  models.Pergunta
+	limite    time.Time     // fim do tempo para responder, sem mais pausas
+	congelado time.Duration // tempo que faltava quando a partida foi pausada
+	pausada   bool
+	respostas chan models.Resposta
+	entrou    chan chegada  // jogador que entrou no meio da pergunta, mais uma resposta esperada
+	esgotado  chan struct{} // fechado quando o tempo para responder acaba
+	fim       chan struct{} // fechado quando a coleta termina
+	esgotou   bool          // o tempo acabou; só quem usou mais_tempo ainda responde
+
+	ajudas    map[*Jogador]map[string]bool // ajudas usadas por jogador nesta pergunta
+	removidas map[*Jogador][]int           // alternativas (índice canônico) tiradas pelo meio_a_meio
+
+	// Modo campainha: os toques chegam em toques e só quem está com a vez responde
+	campainha bool
+	tocando   bool // ainda aceita toques
+	vez       int  // ID de Jogador de quem está com a vez, 0 para ninguém
+	toques    chan models.Resposta
+}
+
+// restante é o tempo que falta para responder. Deve ser chamado com jogadoresMutex travado.
+func (atual *coleta) restante() time.Duration {
+	if atual.pausada {
+		return atual.congelado
+	}
+	return time.Until(atual.limite)
+}
+
+// Coleta respostas e dá feddback. A coleta acaba quando todos respondem, o tempo esgota ou chega um pedido em pular.
+// O tempo da pergunta não corre enquanto a partida está pausada.
+func (server *ServerJogo) ColetarRespostas(ctx context.Context, partidaAtual *partida, tempo_duracao time.Duration, pergunta models.Pergunta) []models.Resposta {
+	jogadores := server.emJogo()
 	var respostas []models.Resposta
-	tempo_limite := time.Now().Add(tempo_duracao)
-	canalResposta := make(chan models.Resposta, len(jogadores))
+	inicio := time.Now()
+	relogio := server.novoRelogio(partidaAtual, tempo_duracao)
+	defer relogio.parar()
+	atual := &coleta{
+		pergunta:  pergunta,
+		limite:    relogio.fim,
+		respostas: make(chan models.Resposta, len(jogadores)),
+		entrou:    make(chan chegada, server.maxJogadores), // uma chegada por jogador conectado
+		esgotado:  make(chan struct{}),
+		fim:       make(chan struct{}),
+	}
+	server.jogadoresMutex.Lock()
+	server.coleta = atual
+	server.jogadoresMutex.Unlock()
+	defer func() {
+		server.jogadoresMutex.Lock()
+		server.coleta = nil
+		server.jogadoresMutex.Unlock()
+		close(atual.fim)
+	}()
 
 	for _, jogador := range jogadores {
-		// Passa a resposta correta para a goroutine que lê a resposta do jogador
-		go server.lerResposta(jogador, tempo_limite, canalResposta, opcaoCorreta)
+		// Passa a pergunta para a goroutine que lê a resposta do jogador
+		go server.lerResposta(jogador, atual)
 	}
 
 	// Continua a coletar respostas até que o tempo se esgote ou todos respondam
-	for i := 0; i < len(jogadores); i++ {
+	prorrogada := false
+	for esperadas := len(jogadores); len(respostas) < esperadas; {
 		select {
-		case resp := <-canalResposta:
+		case resp := <-atual.respostas:
 			respostas = append(respostas, resp)
-		case <-time.After(tempo_limite.Sub(time.Now())):
-			return respostas // O tempo acabou
+			server.metricas.respostas.WithLabelValues(strconv.Itoa(pergunta.ID)).Inc()
+			server.metricas.latenciaResposta.Observe(resp.Tempo.Sub(inicio).Seconds())
+		case nova := <-atual.entrou:
+			if server.entregarAtrasado(atual, nova) {
+				esperadas++
+			}
+		case <-relogio.timer.C:
+			if prorrogada {
+				return respostas // Acabou também o tempo extra
+			}
+			close(atual.esgotado)
+			// Quem usou mais_tempo e ainda não respondeu ganha TempoExtraAjuda
+			server.jogadoresMutex.Lock()
+			atual.esgotou = true
+			respondidas, pendentes := 0, 0
+			for _, jogador := range server.jogadores {
+				if jogador.respondida == atual {
+					respondidas++
+				} else if atual.usouAjuda(jogador, AjudaMaisTempo) {
+					pendentes++
+				}
+			}
+			server.jogadoresMutex.Unlock()
+			if pendentes == 0 {
+				return respostas // O tempo acabou
+			}
+			prorrogada = true
+			esperadas = respondidas + pendentes
+			relogio.prorrogar(TempoExtraAjuda) // o limite fica: quem entra agora não responde mais
+		case <-partidaAtual.pular:
+			return respostas // O anfitrião encerrou a pergunta
+		case <-relogio.pausar:
+			// Durante a pausa o tempo não corre e as respostas são recusadas
+			server.jogadoresMutex.Lock()
+			atual.congelado = relogio.restante()
+			atual.pausada = true
+			server.jogadoresMutex.Unlock()
+			if relogio.congelar(ctx) != nil {
+				return respostas
+			}
+			server.jogadoresMutex.Lock()
+			atual.pausada = false
+			atual.limite = relogio.fim
+			server.jogadoresMutex.Unlock()
+		case <-ctx.Done():
+			return respostas // A partida foi abortada
 		}
 	}
 	return respostas
 }
 
-// Verifica a resposta e envia feedback imediato
-func (server *ServerJogo) lerResposta(jogador *Jogador, tempo_limite time.Time, canal chan<- models.Resposta, opcaoCorreta string) {
-	leitor := bufio.NewReader(jogador.Conn)
-	jogador.Conn.SetReadDeadline(tempo_limite)
+// Espera a resposta que o leitor do jogador já validou e envia feedback imediato
+func (server *ServerJogo) lerResposta(jogador *Jogador, atual *coleta) {
+	pergunta := atual.pergunta
+	log := server.comPartida(jogador.log).With("pergunta", pergunta.ID)
 
-	msg, err := leitor.ReadBytes('\n')
-	if err != nil {
-		return // O jogador não respondeu a tempo
+	var resp models.Resposta
+	esgotado := atual.esgotado
+	for resp.ID != pergunta.ID { // uma resposta validada no instante em que a anterior fechou fica para trás
+		select {
+		case resp = <-jogador.respostas:
+		case <-esgotado:
+			server.jogadoresMutex.Lock()
+			prorrogado := atual.usouAjuda(jogador, AjudaMaisTempo)
+			server.jogadoresMutex.Unlock()
+			if prorrogado {
+				esgotado = nil // continua esperando até o fim do tempo extra
+				continue
+			}
+			server.metricas.tempoEsgotado.Inc()
+			log.Debug("sem resposta no tempo")
+			return // O jogador não respondeu a tempo
+		case <-jogador.fim:
+			log.Debug("jogador saiu sem responder")
+			return
+		case <-atual.fim:
+			return // A partida foi abortada
+		}
 	}
 
-	var resp struct {
-		Opcao string `json:"opcao"`
+	if resp.Pulou {
+		log.Debug("pergunta pulada")
+	} else {
+		// Feedback imediato com a mesma avaliação que vale para os pontos
+		server.jogadoresMutex.Lock()
+		resultado := montarResultado(pergunta, jogador.Idioma, jogador.ordem, Avaliacao{Alternativa: resp.Alternativa, Correta: resp.Correta})
+		server.jogadoresMutex.Unlock()
+		feedbackMsg, _ := json.Marshal(resultado)
+		server.enviar(jogador, append(feedbackMsg, '\n'))
+		log.Debug("resposta recebida", "opcao", resp.Opcao, "correta", resultado.Correta)
 	}
 
-	if err := json.Unmarshal(msg, &resp); err == nil {
-		// Lógica de feedback imediato
-		correta := (resp.Opcao == opcaoCorreta)
-		feedbackMsg := fmt.Sprintf("{\"tipo\":\"resultado_resposta\",\"correta\":%t}\n", correta)
-		_, err := jogador.Conn.Write([]byte(feedbackMsg))
-		if err != nil {
-			fmt.Printf("Erro ao enviar feedback para %s: %v\n", jogador.Nome, err)
-		}
-
-		// Envia a resposta para o canal principal para ser usada no cálculo de pontos
-		canal <- models.Resposta{
-			Jogador: jogador.Nome,
-			Opcao:   resp.Opcao,
-			Tempo:   time.Now(),
-		}
+	// Envia a resposta para o canal principal para ser usada no cálculo de pontos
+	select {
+	case atual.respostas <- models.Resposta{
+		Jogador:     jogador.Nome,
+		JogadorID:   jogador.ID,
+		Opcao:       resp.Opcao,
+		Alternativa: resp.Alternativa,
+		Correta:     resp.Correta,
+		Tempo:       resp.Tempo,
+		RTT:         resp.RTT,
+		Dobro:       resp.Dobro,
+		Pulou:       resp.Pulou,
+	}:
+	case <-atual.fim:
 	}
 }
 
-// Parar para o servidor e desconecta todos os jogadores
-func (server *ServerJogo) Parar() {
-	close(server.sair)
+// Encerrar para de aceitar jogadores e espera a partida em andamento terminar, por até o tempo
+// informado. Se o tempo acabar, a partida é interrompida. No fim, todos recebem servidor_encerrando
+// com o placar. As conexões continuam abertas até Parar.
+func (server *ServerJogo) Encerrar(tempo time.Duration) {
+	server.jogadoresMutex.Lock()
+	server.encerrando = true
+	server.jogadoresMutex.Unlock()
+	server.log.Info("encerrando o servidor", "tempo_limite", tempo.String())
+
 	if server.listener != nil {
 		server.listener.Close()
 	}
+	if server.descoberta != nil {
+		server.descoberta.Close()
+	}
+
+	ctx, cancelar := context.WithTimeout(context.Background(), tempo)
+	defer cancelar()
+	if server.AguardarPartida(ctx) != nil {
+		server.partidaMutex.Lock()
+		if server.partida != nil {
+			server.partida.cancelar(ErrServidorEncerrando)
+		}
+		server.partidaMutex.Unlock()
+		server.AguardarPartida(context.Background())
+	}
+
+	placar := models.Placar{Tipo: "servidor_encerrando", Pontuacoes: server.Placar()}
+	placarBytes, _ := json.Marshal(placar)
+	server.TransmitirMsg(append(placarBytes, '\n'))
+}
+
+// estaEncerrando informa se Encerrar já foi chamado
+func (server *ServerJogo) estaEncerrando() bool {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+	return server.encerrando
+}
+
+// Parar para o servidor e desconecta todos os jogadores. Pode ser chamado mais de uma vez.
+func (server *ServerJogo) Parar() {
+	server.pararOnce.Do(func() {
+		close(server.sair)
+		if server.listener != nil {
+			server.listener.Close()
+		}
+		if server.httpServer != nil {
+			server.httpServer.Close()
+		}
+		if server.descoberta != nil {
+			server.descoberta.Close()
+		}
+	})
 }
 
 // GetLocalIP busca o endereço IP local
@@ -0,0 +1,60 @@
+// Descoberta na rede local: o servidor responde às procuras enviadas por broadcast UDP
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"triviaMultiplayer/internal/models"
+)
+
+// PortaDescoberta é a porta UDP onde os servidores escutam as procuras dos clientes
+const PortaDescoberta = 8089
+
+// IniciarDescoberta escuta procuras na rede local e responde com o nome do servidor,
+// as salas (com o código de entrada) e o número de jogadores. portaJogo é a porta TCP
+// onde os clientes se conectam.
+func (server *ServerJogo) IniciarDescoberta(nome string, portaJogo int) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: PortaDescoberta})
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar a descoberta na rede local: %w", err)
+	}
+	server.descoberta = conn
+
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			n, origem, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return // conexão fechada em Parar
+			}
+
+			var msg models.Mensagem
+			if json.Unmarshal(buffer[:n], &msg) != nil || msg.Tipo != "procurar_servidores" {
+				continue
+			}
+
+			anuncio, _ := json.Marshal(server.anuncio(nome, portaJogo))
+			if _, err := conn.WriteToUDP(anuncio, origem); err != nil {
+				server.log.Warn("erro ao responder procura na rede local", "destino", origem.String(), "erro", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// anuncio monta a descrição atual do servidor
+func (server *ServerJogo) anuncio(nome string, portaJogo int) models.Anuncio {
+	jogadores := server.RetornarNumJogadores()
+	return models.Anuncio{
+		Tipo:      "anuncio",
+		Nome:      nome,
+		Porta:     portaJogo,
+		TLS:       server.tlsConfig != nil,
+		Jogadores: jogadores,
+		Salas: []models.SalaAnuncio{
+			{Nome: server.sala, Codigo: server.codigo, Jogadores: jogadores, Maximo: server.maxJogadores},
+		},
+	}
+}
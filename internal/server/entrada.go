@@ -0,0 +1,150 @@
+// Entrada tardia: o que acontece com quem conecta enquanto uma partida está em andamento
+
+package server
+
+import (
+	"encoding/json"
+	"math/rand"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Políticas de entrada tardia da sala
+const (
+	EntradaFila  = "fila"  // espera a próxima partida
+	EntradaZero  = "zero"  // entra na partida atual com zero pontos
+	EntradaMenor = "menor" // entra na partida atual com a menor pontuação entre os jogadores
+)
+
+// posicionarAtrasado aplica a política a um jogador que chegou no meio da partida.
+// Deve ser chamado com partidaMutex e jogadoresMutex travados, antes de o jogador entrar na lista.
+func (server *ServerJogo) posicionarAtrasado(jogador *Jogador) {
+	if server.partida.vidas > 0 {
+		jogador.aguardando = true // nos modos com vidas, todos começam juntos
+		return
+	}
+	switch server.config.EntradaTardia {
+	case EntradaZero:
+		jogador.Pontuacao = 0
+	case EntradaMenor:
+		primeiro := true
+		for _, outro := range server.jogadores {
+			if outro.aguardando {
+				continue
+			}
+			if primeiro || outro.Pontuacao < jogador.Pontuacao {
+				jogador.Pontuacao = outro.Pontuacao
+				primeiro = false
+			}
+		}
+	default:
+		jogador.aguardando = true
+		return
+	}
+	jogador.ajudas = novasAjudas(server.config.Ajudas)
+}
+
+// avisarAtrasado manda ao jogador que chegou no meio da partida a situação atual: ou que ele
+// espera a próxima, ou o placar e, se ainda houver tempo, a pergunta aberta com o tempo restante
+func (server *ServerJogo) avisarAtrasado(jogador *Jogador, estado EstadoPartida, config Config, atual *coleta) {
+	server.jogadoresMutex.Lock()
+	aguardando := jogador.aguardando
+	pontos := jogador.Pontuacao
+	server.jogadoresMutex.Unlock()
+
+	if aguardando {
+		jogador.log.Info("jogador espera a próxima partida", "pergunta", estado.Pergunta)
+		aviso, _ := json.Marshal(models.EntradaPartida{Tipo: "aguardando_partida", Pergunta: estado.Pergunta, Total: estado.Total})
+		server.enviar(jogador, append(aviso, '\n'))
+		return
+	}
+
+	server.comPartida(jogador.log).Info("jogador entrou no meio da partida", "pergunta", estado.Pergunta, "pontos", pontos)
+	aviso, _ := json.Marshal(models.EntradaPartida{Tipo: "entrada_partida", Pergunta: estado.Pergunta, Total: estado.Total, Pontuacoes: estado.Placar})
+	server.enviar(jogador, append(aviso, '\n'))
+
+	if atual == nil {
+		return
+	}
+	server.jogadoresMutex.Lock()
+	ordem := rand.Perm(len(atual.pergunta.Opcoes))
+	if !config.EmbaralharPorJogador {
+		// Mesma ordem que os outros receberam
+		for _, outro := range server.jogadores {
+			if outro != jogador && !outro.aguardando && len(outro.ordem) == len(ordem) {
+				ordem = outro.ordem
+				break
+			}
+		}
+	}
+	server.jogadoresMutex.Unlock()
+
+	// A coleta manda a pergunta quando receber a chegada; durante uma pausa isso só acontece na
+	// retomada, já com o tempo que falta. O canal cabe um jogador por vaga, então não bloqueia.
+	select {
+	case atual.entrou <- chegada{jogador: jogador, ordem: ordem}:
+	case <-atual.fim:
+	}
+}
+
+// chegada é um jogador que entrou no meio da pergunta, com a ordem das alternativas dele
+type chegada struct {
+	jogador *Jogador
+	ordem   []int
+}
+
+// entregarAtrasado manda a pergunta aberta a quem chegou no meio dela, com o tempo que falta
+// agora. Chamado pela goroutine da coleta, fora de uma pausa. Retorna false se não der mais
+// tempo de ler a pergunta: o jogador joga a partir da próxima.
+func (server *ServerJogo) entregarAtrasado(atual *coleta, nova chegada) bool {
+	server.jogadoresMutex.Lock()
+	restante := atual.restante()
+	if restante < time.Second {
+		server.jogadoresMutex.Unlock()
+		return false
+	}
+	pergunta := atual.pergunta
+	pergunta.Tempo = segundosRestantes(restante)
+	server.enviarPergunta(nova.jogador, mensagemPergunta(nova.jogador, pergunta, nova.ordem))
+	nome := nova.jogador.Nome
+	server.jogadoresMutex.Unlock()
+
+	go server.lerResposta(nova.jogador, atual)
+	server.registrarVista(pergunta, []string{nome})
+	return true
+}
+
+// participantes retorna os jogadores da partida atual, sem os que esperam a próxima
+func (server *ServerJogo) participantes() []*Jogador {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	jogadores := make([]*Jogador, 0, len(server.jogadores))
+	for _, jogador := range server.jogadores {
+		if !jogador.aguardando {
+			jogadores = append(jogadores, jogador)
+		}
+	}
+	return jogadores
+}
+
+// transmitirPartida envia uma mensagem da partida só para quem está jogando
+func (server *ServerJogo) transmitirPartida(msg []byte) {
+	for _, jogador := range server.participantes() {
+		server.enviar(jogador, msg)
+	}
+}
+
+// liberarFila faz quem esperava e quem foi eliminado entrar na próxima partida
+func (server *ServerJogo) liberarFila() {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	for _, jogador := range server.jogadores {
+		jogador.aguardando = false
+		jogador.eliminado = false
+		jogador.vidas = 0
+		jogador.ordemEliminacao = 0
+		jogador.ajudas = nil
+	}
+}
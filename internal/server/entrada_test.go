@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+// conectarTeste liga um jogador ao servidor por um net.Pipe, com a escritora rodando, e retorna
+// as mensagens que o cliente recebe
+func conectarTeste(t *testing.T, server *ServerJogo, nome string) (*Jogador, <-chan map[string]any) {
+	lado, cliente := net.Pipe()
+	jogador := server.novoJogador(lado, "127.0.0.1")
+	jogador.Nome = nome
+	go server.escritor(jogador)
+	t.Cleanup(func() {
+		jogador.encerrar()
+		cliente.Close()
+		lado.Close()
+	})
+
+	mensagens := make(chan map[string]any, 64)
+	go func() {
+		defer close(mensagens)
+		linhas := bufio.NewScanner(cliente)
+		for linhas.Scan() {
+			var msg map[string]any
+			if json.Unmarshal(linhas.Bytes(), &msg) == nil {
+				mensagens <- msg
+			}
+		}
+	}()
+	return jogador, mensagens
+}
+
+// partidaTeste põe no servidor uma partida de uma pergunta, sem a goroutine que a executa
+func partidaTeste(server *ServerJogo) *partida {
+	atual := &partida{
+		id:       1,
+		log:      server.log,
+		fim:      make(chan struct{}),
+		pausar:   make(chan struct{}),
+		pular:    make(chan struct{}, 1),
+		pergunta: 1,
+		total:    1,
+	}
+	server.partida = atual
+	return atual
+}
+
+// esperarMensagem descarta as mensagens até chegar uma do tipo pedido
+func esperarMensagem(t *testing.T, mensagens <-chan map[string]any, tipo string) map[string]any {
+	t.Helper()
+	limite := time.After(2 * time.Second)
+	for {
+		select {
+		case msg, ok := <-mensagens:
+			if !ok {
+				t.Fatalf("conexão fechada antes de %q", tipo)
+			}
+			if msg["tipo"] == tipo {
+				return msg
+			}
+		case <-limite:
+			t.Fatalf("%q não chegou", tipo)
+		}
+	}
+}
+
+// semMensagem falha se chegar uma mensagem do tipo pedido durante o tempo
+func semMensagem(t *testing.T, mensagens <-chan map[string]any, tipo string, tempo time.Duration) {
+	t.Helper()
+	limite := time.After(tempo)
+	for {
+		select {
+		case msg, ok := <-mensagens:
+			if !ok {
+				return
+			}
+			if msg["tipo"] == tipo {
+				t.Fatalf("recebeu %q: %v", tipo, msg)
+			}
+		case <-limite:
+			return
+		}
+	}
+}
+
+func TestEntrarDuranteAPausa(t *testing.T) {
+	server := NovoServer(4)
+	server.config.EntradaTardia = EntradaZero
+	atual := partidaTeste(server)
+
+	ana, deAna := conectarTeste(t, server, "ana")
+	server.jogadores = append(server.jogadores, ana)
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	defer cancelar()
+	const tempo = 10 * time.Second
+	go server.ColetarRespostas(ctx, atual, tempo, perguntaTeste)
+	for aberta := false; !aberta; time.Sleep(time.Millisecond) {
+		server.jogadoresMutex.Lock()
+		aberta = server.coleta != nil
+		server.jogadoresMutex.Unlock()
+	}
+
+	if err := server.Pausar(); err != nil {
+		t.Fatal(err)
+	}
+	esperarMensagem(t, deAna, "pausa")
+
+	// Quem entra durante a pausa não pode travar a conexão esperando a retomada
+	bia, deBia := conectarTeste(t, server, "bia")
+	entrou := make(chan struct{})
+	go func() {
+		server.addJogador(bia)
+		close(entrou)
+	}()
+	select {
+	case <-entrou:
+	case <-time.After(time.Second):
+		t.Fatal("addJogador ficou bloqueado durante a pausa")
+	}
+	esperarMensagem(t, deBia, "entrada_partida")
+	semMensagem(t, deBia, "pergunta", 200*time.Millisecond)
+
+	if err := server.Retomar(); err != nil {
+		t.Fatal(err)
+	}
+	pergunta := esperarMensagem(t, deBia, "pergunta")
+	if segundos, _ := pergunta["tempo"].(float64); segundos < 9 || segundos > 10 {
+		t.Fatalf("pergunta chegou com tempo %v, esperava o tempo congelado de %v", pergunta["tempo"], tempo)
+	}
+	// Depois da pergunta, nenhuma pausa velha pode congelar o cronômetro de quem entrou
+	semMensagem(t, deBia, "pausa", 300*time.Millisecond)
+}
@@ -0,0 +1,137 @@
+// Envio de mensagens: cada jogador tem uma fila e uma goroutine escritora, para um cliente lento
+// não travar a partida nem os outros jogadores
+
+package server
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Valores padrão da fila de envio de cada jogador
+const (
+	TamanhoFilaPadrao  = 64
+	PrazoEscritaPadrao = 5 * time.Second
+)
+
+// mensagem é um item da fila de envio. As perguntas são marcadas para o tempo de reação do
+// jogador contar a partir de quando a pergunta foi de fato escrita na conexão.
+type mensagem struct {
+	dados    []byte
+	pergunta bool
+}
+
+// ConfigurarEnvio define o tamanho da fila de cada jogador e o prazo de cada escrita.
+// Um jogador com a fila cheia ou que estoura o prazo é desconectado.
+func (server *ServerJogo) ConfigurarEnvio(tamanhoFila int, prazoEscrita time.Duration) {
+	server.tamanhoFila = tamanhoFila
+	server.prazoEscrita = prazoEscrita
+}
+
+// novoJogador prepara o jogador com a sua fila de envio vazia e ainda sem ping medido
+func (server *ServerJogo) novoJogador(conn net.Conn, ip string) *Jogador {
+	jogador := &Jogador{
+		ID:          server.novoIDJogador(),
+		IP:          ip,
+		Conn:        conn,
+		fila:        make(chan mensagem, server.tamanhoFila),
+		fim:         make(chan struct{}),
+		fimOnce:     &sync.Once{},
+		escritorFim: make(chan struct{}),
+		leitor:      bufio.NewReader(conn),
+		respostas:   make(chan models.Resposta, 1),
+		rtt:         -1,
+	}
+	jogador.log = server.log.With("jogador", jogador.ID, "ip", ip)
+	return jogador
+}
+
+// enviar coloca a mensagem na fila do jogador sem bloquear. Se a fila estiver cheia,
+// o jogador não está acompanhando o jogo e é desconectado.
+func (server *ServerJogo) enviar(jogador *Jogador, msg []byte) bool {
+	return server.enfileirar(jogador, mensagem{dados: msg})
+}
+
+// enviarPergunta coloca uma pergunta na fila do jogador, como enviar
+func (server *ServerJogo) enviarPergunta(jogador *Jogador, msg []byte) bool {
+	return server.enfileirar(jogador, mensagem{dados: msg, pergunta: true})
+}
+
+// enfileirar coloca o item na fila sem bloquear, desconectando o jogador se ela estiver cheia
+func (server *ServerJogo) enfileirar(jogador *Jogador, msg mensagem) bool {
+	select {
+	case <-jogador.fim:
+		return false
+	default:
+	}
+
+	select {
+	case jogador.fila <- msg:
+		return true
+	default:
+		server.metricas.jogadoresLentos.Inc()
+		jogador.log.Warn("fila de envio cheia, desconectando jogador lento", "tamanho_fila", cap(jogador.fila))
+		jogador.encerrar()
+		return false
+	}
+}
+
+// encerrar pede o fim da conexão do jogador; as mensagens já na fila ainda são enviadas
+func (jogador *Jogador) encerrar() {
+	jogador.fimOnce.Do(func() { close(jogador.fim) })
+}
+
+// escritor envia as mensagens da fila, uma de cada vez, com prazo para cada escrita
+func (server *ServerJogo) escritor(jogador *Jogador) {
+	defer close(jogador.escritorFim)
+	for {
+		select {
+		case msg := <-jogador.fila:
+			if !server.escrever(jogador, msg) {
+				jogador.encerrar()
+				return
+			}
+		case <-jogador.fim:
+			// Esvazia o que já estava na fila, como o aviso de expulsão ou o placar do encerramento
+			for {
+				select {
+				case msg := <-jogador.fila:
+					if !server.escrever(jogador, msg) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// escrever faz uma escrita com prazo; um erro ou prazo estourado desconecta o jogador.
+// Uma pergunta marca a hora em que sai, para medir o tempo de reação.
+func (server *ServerJogo) escrever(jogador *Jogador, msg mensagem) bool {
+	if msg.pergunta {
+		server.jogadoresMutex.Lock()
+		jogador.perguntaEm = time.Now()
+		server.jogadoresMutex.Unlock()
+	}
+	jogador.Conn.SetWriteDeadline(time.Now().Add(server.prazoEscrita))
+	if _, err := jogador.Conn.Write(msg.dados); err != nil {
+		server.metricas.errosEscrita.Inc()
+		jogador.log.Warn("erro ao enviar mensagem, desconectando jogador", "erro", err)
+		return false
+	}
+	return true
+}
+
+// recusar avisa o cliente do motivo e fecha a conexão antes de ele virar jogador
+func (server *ServerJogo) recusar(conn net.Conn, ip, motivo string) {
+	server.metricas.conexoesRecusadas.WithLabelValues(motivo).Inc()
+	server.log.Info("conexão recusada", "motivo", motivo, "ip", ip)
+	conn.SetWriteDeadline(time.Now().Add(server.prazoEscrita))
+	conn.Write([]byte("{\"tipo\":\"" + motivo + "\"}\n"))
+	conn.Close()
+}
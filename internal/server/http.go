@@ -0,0 +1,76 @@
+// Servidor HTTP: cliente web embutido e gateway WebSocket para o mesmo jogo dos clientes TCP
+
+package server
+
+import (
+	"crypto/tls"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"strings"
+	"triviaMultiplayer/internal/idioma"
+
+	"golang.org/x/net/websocket"
+)
+
+//go:embed web
+var arquivosWeb embed.FS
+
+// IniciarHTTP serve o cliente web, o endpoint /ws e as métricas em /metrics no endereço informado.
+// Usa o mesmo certificado TLS do servidor de jogo, se houver.
+func (server *ServerJogo) IniciarHTTP(endereco string) error {
+	listener, err := net.Listen("tcp", endereco)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar servidor HTTP: %w", err)
+	}
+	if server.tlsConfig != nil {
+		listener = tls.NewListener(listener, server.tlsConfig)
+	}
+
+	web, _ := fs.Sub(arquivosWeb, "web")
+	server.mux.Handle("/", http.FileServer(http.FS(web)))
+	server.mux.HandleFunc("/idioma/", servirCatalogo)
+	server.mux.Handle("/ws", websocket.Server{Handler: server.conectarWebSocket, Handshake: conferirOrigem})
+	server.habilitarMetricas()
+
+	server.httpServer = &http.Server{Handler: server.mux}
+	go server.httpServer.Serve(listener)
+	return nil
+}
+
+// conectarWebSocket trata o navegador como mais um jogador. As mensagens continuam
+// sendo JSON terminadas em '\n', cada uma num quadro de texto. O IP vem da requisição,
+// porque o RemoteAddr do WebSocket é a origem da página.
+func (server *ServerJogo) conectarWebSocket(ws *websocket.Conn) {
+	ws.PayloadType = websocket.TextFrame
+	server.admitir(ws, hostDe(ws.Request().RemoteAddr))
+}
+
+// conferirOrigem só aceita o WebSocket aberto pela página deste servidor: um Origin de outro
+// host seria outra página usando o navegador do jogador para jogar por ele. Clientes fora do
+// navegador não mandam Origin e passam.
+func conferirOrigem(config *websocket.Config, r *http.Request) error {
+	origem, err := websocket.Origin(config, r)
+	if err != nil || origem == nil {
+		return err
+	}
+	config.Origin = origem
+	if !strings.EqualFold(origem.Host, r.Host) {
+		return fmt.Errorf("origem %q não é deste servidor (%s)", origem.Host, r.Host)
+	}
+	return nil
+}
+
+// servirCatalogo entrega o catálogo de mensagens para o cliente web (ex: /idioma/en-US.json)
+func servirCatalogo(w http.ResponseWriter, r *http.Request) {
+	tag := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/idioma/"), ".json")
+	catalogo, ok := idioma.Catalogo(idioma.Escolher(tag))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(catalogo)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestConferirOrigem(t *testing.T) {
+	casos := []struct {
+		nome   string
+		origem string
+		aceita bool
+	}{
+		{"página do próprio servidor", "http://192.168.0.10:8080", true},
+		{"outra página", "https://exemplo.com", false},
+		{"mesmo IP em outra porta", "http://192.168.0.10:9000", false},
+		{"cliente sem navegador, sem Origin", "", true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://192.168.0.10:8080/ws", nil)
+			if caso.origem != "" {
+				r.Header.Set("Origin", caso.origem)
+			}
+			config := &websocket.Config{Version: websocket.ProtocolVersionHybi13}
+			if err := conferirOrigem(config, r); (err == nil) != caso.aceita {
+				t.Fatalf("conferirOrigem(%q) = %v, esperava aceitar = %v", caso.origem, err, caso.aceita)
+			}
+		})
+	}
+}
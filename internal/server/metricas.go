@@ -0,0 +1,112 @@
+// Métricas no formato do Prometheus, expostas em /metrics no servidor HTTP
+
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricas agrupa os contadores do servidor num registro próprio
+type metricas struct {
+	registro            *prometheus.Registry
+	conexoesAceitas     prometheus.Counter
+	conexoesRecusadas   *prometheus.CounterVec
+	jogadoresAtivos     prometheus.Gauge
+	partidas            *prometheus.CounterVec
+	respostas           *prometheus.CounterVec
+	latenciaResposta    prometheus.Histogram
+	errosEscrita        prometheus.Counter
+	jogadoresLentos     prometheus.Counter
+	tempoEsgotado       prometheus.Counter
+	semResposta         prometheus.Counter
+	rtt                 prometheus.Histogram
+	mensagensRejeitadas *prometheus.CounterVec
+	respostasSuspeitas  prometheus.Counter
+}
+
+// novasMetricas cria e registra as métricas do jogo e as do processo Go
+func novasMetricas() *metricas {
+	m := &metricas{
+		registro: prometheus.NewRegistry(),
+		conexoesAceitas: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_conexoes_aceitas_total",
+			Help: "Conexões de jogadores aceitas (TCP e WebSocket).",
+		}),
+		conexoesRecusadas: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "trivia_conexoes_recusadas_total",
+			Help: "Conexões recusadas, por motivo (servidor_lotado, banido ou servidor_encerrando).",
+		}, []string{"motivo"}),
+		jogadoresAtivos: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "trivia_jogadores_ativos",
+			Help: "Jogadores conectados no momento.",
+		}),
+		partidas: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "trivia_partidas_total",
+			Help: "Partidas jogadas, por resultado (completa ou abortada).",
+		}, []string{"resultado"}),
+		respostas: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "trivia_respostas_total",
+			Help: "Respostas recebidas dentro do tempo, por número da pergunta na partida.",
+		}, []string{"pergunta"}),
+		latenciaResposta: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "trivia_latencia_resposta_segundos",
+			Help:    "Tempo entre o envio da pergunta e a chegada da resposta.",
+			Buckets: []float64{0.5, 1, 2, 3, 5, 7.5, 10, 15, 30},
+		}),
+		errosEscrita: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_erros_escrita_total",
+			Help: "Falhas ou prazos estourados ao escrever uma mensagem para um jogador.",
+		}),
+		jogadoresLentos: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_jogadores_lentos_desconectados_total",
+			Help: "Jogadores desconectados por deixar a fila de envio encher.",
+		}),
+		tempoEsgotado: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_respostas_tempo_esgotado_total",
+			Help: "Perguntas que um jogador deixou sem resposta até o fim do tempo.",
+		}),
+		semResposta: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_jogadores_sem_resposta_desconectados_total",
+			Help: "Jogadores desconectados por não responder aos pings.",
+		}),
+		rtt: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "trivia_ping_segundos",
+			Help:    "Tempo de ida e volta entre o ping do servidor e o pong do jogador.",
+			Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+		}),
+		mensagensRejeitadas: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "trivia_mensagens_rejeitadas_total",
+			Help: "Mensagens de jogadores descartadas pela validação, por motivo.",
+		}, []string{"motivo"}),
+		respostasSuspeitas: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "trivia_respostas_suspeitas_total",
+			Help: "Respostas mais rápidas que o tempo mínimo de reação humana.",
+		}),
+	}
+
+	m.registro.MustRegister(
+		m.conexoesAceitas,
+		m.conexoesRecusadas,
+		m.jogadoresAtivos,
+		m.partidas,
+		m.respostas,
+		m.latenciaResposta,
+		m.errosEscrita,
+		m.jogadoresLentos,
+		m.tempoEsgotado,
+		m.semResposta,
+		m.rtt,
+		m.mensagensRejeitadas,
+		m.respostasSuspeitas,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// habilitarMetricas registra /metrics no servidor HTTP
+func (server *ServerJogo) habilitarMetricas() {
+	server.mux.Handle("/metrics", promhttp.HandlerFor(server.metricas.registro, promhttp.HandlerOpts{}))
+}
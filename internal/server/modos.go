@@ -0,0 +1,335 @@
+// Modos de jogo: as regras de cada partida (quantas perguntas, como pontuar, quando acaba)
+// ficam num ModoJogo escolhido pela configuração da sala
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Modos de jogo disponíveis
+const (
+	ModoClassico      = "classico"       // num_perguntas perguntas, 100 para o primeiro a acertar e metade para cada seguinte
+	ModoSobrevivencia = "sobrevivencia"  // errar ou não responder elimina; acaba quando sobra um jogador
+	ModoEliminacao    = "eliminacao"     // cada erro custa uma vida; sem vidas, o jogador passa a assistir
+	ModoMorteSubita   = "morte_subita"   // a primeira pergunta que alguém acerta decide a partida
+	ModoContraRelogio = "contra_relogio" // quantos acertos couberem em duracao_contra_relogio segundos
+	ModoReiDaColina   = "rei_da_colina"  // quem acerta primeiro vira rei; o rei pontua enquanto mantém a coroa
+	ModoCampainha     = "campainha"      // só responde quem toca a campainha primeiro; errou, a vez passa ao próximo
+)
+
+// PontosPorAcerto é quanto vale cada acerto nos modos sem disputa de velocidade
+const PontosPorAcerto = 100
+
+// ModoJogo são as regras de um modo de jogo. A partida sorteia Perguntas, chama Preparar e, enquanto
+// não Terminou, pede a ProximaPergunta, manda Coletar as respostas e as passa para Pontuar.
+type ModoJogo interface {
+	// Perguntas diz quantas perguntas sortear; 0 sorteia todas as disponíveis
+	Perguntas(config Config) int
+	// Preparar recebe as perguntas sorteadas, os jogadores e a configuração antes da contagem
+	Preparar(perguntas []models.Pergunta, jogadores []string, config Config)
+	// ProximaPergunta retorna a pergunta seguinte e o tempo para respondê-la
+	ProximaPergunta(decorrido time.Duration) (models.Pergunta, time.Duration)
+	// Coletar envia a pergunta aos jogadores em jogo e junta as respostas
+	Coletar(ctx context.Context, server *ServerJogo, atual *partida, pergunta models.Pergunta, tempo time.Duration) []models.Resposta
+	// Pontuar distribui os pontos da pergunta; emJogo são os jogadores que deviam responder
+	Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada
+	// Terminou informa se a partida acabou, pelo tempo de jogo sem as pausas e pelos jogadores em jogo
+	Terminou(decorrido time.Duration, emJogo int) bool
+	// Intervalos diz quanto tempo a resposta certa e o placar parcial ficam na tela; zero pula a etapa
+	Intervalos() (revelacao, placar time.Duration)
+	// Vidas diz com quantas vidas cada jogador começa; 0 para modos sem vidas
+	Vidas() int
+}
+
+// Rodada é o resultado de uma pergunta
+type Rodada struct {
+	Pontos       []models.Pontuacao
+	PerderamVida []*Jogador // quem fica sem vidas é eliminado e passa a só assistir
+}
+
+// NovoModo cria as regras do modo pedido
+func NovoModo(nome string) (ModoJogo, error) {
+	switch nome {
+	case ModoClassico:
+		return &modoClassico{}, nil
+	case ModoSobrevivencia:
+		return &modoSobrevivencia{}, nil
+	case ModoEliminacao:
+		return &modoEliminacao{}, nil
+	case ModoMorteSubita:
+		return &modoMorteSubita{}, nil
+	case ModoContraRelogio:
+		return &modoContraRelogio{}, nil
+	case ModoReiDaColina:
+		return &modoReiDaColina{}, nil
+	case ModoCampainha:
+		return &modoCampainha{}, nil
+	}
+	return nil, fmt.Errorf("modo deve ser %s, %s, %s, %s, %s, %s ou %s",
+		ModoClassico, ModoSobrevivencia, ModoEliminacao, ModoMorteSubita, ModoContraRelogio, ModoReiDaColina, ModoCampainha)
+}
+
+// modoClassico é o jogo original, e a base dos outros modos
+type modoClassico struct {
+	perguntas []models.Pergunta
+	proxima   int
+	jogadores int
+	config    Config
+}
+
+func (modo *modoClassico) Perguntas(config Config) int {
+	return config.NumPerguntas
+}
+
+func (modo *modoClassico) Preparar(perguntas []models.Pergunta, jogadores []string, config Config) {
+	modo.perguntas = perguntas
+	modo.jogadores = len(jogadores)
+	modo.config = config
+}
+
+func (modo *modoClassico) ProximaPergunta(decorrido time.Duration) (models.Pergunta, time.Duration) {
+	pergunta := modo.perguntas[modo.proxima]
+	modo.proxima++
+	return pergunta, time.Duration(modo.config.TempoResposta) * time.Second
+}
+
+func (modo *modoClassico) Coletar(ctx context.Context, server *ServerJogo, atual *partida, pergunta models.Pergunta, tempo time.Duration) []models.Resposta {
+	server.EnviarPergunta(pergunta, modo.config.EmbaralharPorJogador)
+	return server.ColetarRespostas(ctx, atual, tempo, pergunta)
+}
+
+func (modo *modoClassico) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	return Rodada{Pontos: CalcularPontos(respostas, modo.config.Desempate())}
+}
+
+func (modo *modoClassico) Terminou(decorrido time.Duration, emJogo int) bool {
+	return modo.proxima >= len(modo.perguntas) || emJogo == 0
+}
+
+func (modo *modoClassico) Intervalos() (time.Duration, time.Duration) {
+	return time.Duration(modo.config.TempoRevelacao) * time.Second, time.Duration(modo.config.TempoPlacar) * time.Second
+}
+
+func (modo *modoClassico) Vidas() int {
+	return 0
+}
+
+// modoEliminacao tira uma vida de quem erra ou não responde. Pontua como o clássico e segue
+// com todas as perguntas disponíveis até sobrar um jogador, ou nenhum.
+type modoEliminacao struct {
+	modoClassico
+}
+
+func (modo *modoEliminacao) Perguntas(config Config) int {
+	return 0
+}
+
+func (modo *modoEliminacao) Vidas() int {
+	return modo.config.Vidas
+}
+
+func (modo *modoEliminacao) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	rodada := modo.modoClassico.Pontuar(respostas, emJogo)
+	rodada.PerderamVida = semAcerto(respostas, emJogo)
+	return rodada
+}
+
+func (modo *modoEliminacao) Terminou(decorrido time.Duration, emJogo int) bool {
+	// Sozinho, joga até cair; com mais gente, até sobrar um
+	return modo.modoClassico.Terminou(decorrido, emJogo) || (modo.jogadores > 1 && emJogo <= 1)
+}
+
+// modoSobrevivencia é a eliminação com uma vida só, em que ninguém sai se todos erram.
+// num_perguntas é o máximo de perguntas se ninguém cair.
+type modoSobrevivencia struct {
+	modoEliminacao
+}
+
+func (modo *modoSobrevivencia) Perguntas(config Config) int {
+	return config.NumPerguntas
+}
+
+func (modo *modoSobrevivencia) Vidas() int {
+	return 1
+}
+
+func (modo *modoSobrevivencia) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	rodada := modo.modoEliminacao.Pontuar(respostas, emJogo)
+	if len(emJogo) > 1 && len(rodada.PerderamVida) == len(emJogo) {
+		rodada.PerderamVida = nil // se todos erram, ninguém sai
+	}
+	return rodada
+}
+
+// semAcerto retorna os jogadores em jogo que erraram ou não responderam, pelo ID para não
+// confundir jogadores com o mesmo nome
+func semAcerto(respostas []models.Resposta, emJogo []*Jogador) []*Jogador {
+	acertaram := make(map[int]bool, len(respostas))
+	for _, resp := range respostas {
+		if resp.Correta || resp.Pulou { // pular a pergunta não custa vida
+			acertaram[resp.JogadorID] = true
+		}
+	}
+	var eliminados []*Jogador
+	for _, jogador := range emJogo {
+		if !acertaram[jogador.ID] {
+			eliminados = append(eliminados, jogador)
+		}
+	}
+	return eliminados
+}
+
+// modoMorteSubita segue pergunta a pergunta até alguém acertar: quem acertou primeiro leva
+// os pontos e a partida acaba. num_perguntas é o máximo de perguntas se ninguém acertar.
+type modoMorteSubita struct {
+	modoClassico
+	decidida bool
+}
+
+func (modo *modoMorteSubita) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	var vencedores []models.Pontuacao
+	pontos := CalcularPontos(respostas, modo.config.Desempate())
+	for _, ponto := range pontos {
+		if ponto.Pontos == pontos[0].Pontos { // os empatados em primeiro
+			vencedores = append(vencedores, ponto)
+		}
+	}
+	modo.decidida = len(vencedores) > 0
+	return Rodada{Pontos: vencedores}
+}
+
+func (modo *modoMorteSubita) Terminou(decorrido time.Duration, emJogo int) bool {
+	return modo.decidida || modo.modoClassico.Terminou(decorrido, emJogo)
+}
+
+// modoContraRelogio manda perguntas até acabar duracao_contra_relogio. Cada acerto vale
+// PontosPorAcerto, sem disputa de velocidade, e a pergunta fecha assim que todos respondem.
+type modoContraRelogio struct {
+	modoClassico
+}
+
+func (modo *modoContraRelogio) Perguntas(config Config) int {
+	return 0
+}
+
+func (modo *modoContraRelogio) duracao() time.Duration {
+	return time.Duration(modo.config.DuracaoContraRelogio) * time.Second
+}
+
+func (modo *modoContraRelogio) ProximaPergunta(decorrido time.Duration) (models.Pergunta, time.Duration) {
+	pergunta, tempo := modo.modoClassico.ProximaPergunta(decorrido)
+	if restante := modo.duracao() - decorrido; restante < tempo {
+		tempo = restante // a última pergunta acaba junto com o relógio
+	}
+	return pergunta, tempo
+}
+
+func (modo *modoContraRelogio) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	var rodada Rodada
+	for _, resp := range respostas {
+		if resp.Correta {
+			rodada.Pontos = append(rodada.Pontos, models.Pontuacao{Jogador: resp.Jogador, Pontos: PontosPorAcerto})
+		}
+	}
+	return rodada
+}
+
+func (modo *modoContraRelogio) Terminou(decorrido time.Duration, emJogo int) bool {
+	// Menos de um segundo não dá para ler uma pergunta
+	return modo.duracao()-decorrido < time.Second || modo.modoClassico.Terminou(decorrido, emJogo)
+}
+
+func (modo *modoContraRelogio) Intervalos() (time.Duration, time.Duration) {
+	return time.Second, 0 // só o bastante para ver se acertou
+}
+
+// modoReiDaColina coroa quem acerta primeiro. Ao fim de cada pergunta o rei ganha
+// PontosPorAcerto; ele perde a coroa quando outro acerta antes dele.
+type modoReiDaColina struct {
+	modoClassico
+	rei string
+}
+
+func (modo *modoReiDaColina) Pontuar(respostas []models.Resposta, emJogo []*Jogador) Rodada {
+	if pontos := CalcularPontos(respostas, modo.config.Desempate()); len(pontos) > 0 {
+		modo.rei = pontos[0].Jogador
+	}
+	for _, jogador := range emJogo {
+		if jogador.Nome == modo.rei {
+			return Rodada{Pontos: []models.Pontuacao{{Jogador: modo.rei, Pontos: PontosPorAcerto}}}
+		}
+	}
+	modo.rei = "" // o rei saiu; a coroa fica com o próximo a acertar primeiro
+	return Rodada{}
+}
+
+// emJogo retorna quem responde às perguntas: os participantes que não foram eliminados
+func (server *ServerJogo) emJogo() []*Jogador {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	var jogadores []*Jogador
+	for _, jogador := range server.jogadores {
+		if !jogador.aguardando && !jogador.eliminado {
+			jogadores = append(jogadores, jogador)
+		}
+	}
+	return jogadores
+}
+
+// jogadoresEmJogo retorna os nomes de quem responde às perguntas
+func (server *ServerJogo) jogadoresEmJogo() []string {
+	var nomes []string
+	for _, jogador := range server.emJogo() {
+		nomes = append(nomes, jogador.Nome)
+	}
+	return nomes
+}
+
+// darVidas começa a partida com as vidas do modo para cada jogador em jogo
+func (server *ServerJogo) darVidas(atual *partida, vidas int) {
+	server.partidaMutex.Lock()
+	atual.vidas = vidas
+	server.partidaMutex.Unlock()
+
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+	for _, jogador := range server.jogadores {
+		if !jogador.aguardando {
+			jogador.vidas = vidas
+		}
+	}
+}
+
+// tirarVida desconta uma vida do jogador. Sem vidas, ele sai das próximas perguntas e passa a
+// assistir: continua recebendo as perguntas (sem poder responder), a revelação e o placar.
+// Quem cai na mesma pergunta divide a ordem de eliminação.
+func (server *ServerJogo) tirarVida(atual *partida, alvo *Jogador, ordem int) {
+	server.jogadoresMutex.Lock()
+	var eliminado *Jogador
+	for _, jogador := range server.jogadores {
+		if jogador != alvo || jogador.eliminado {
+			continue // quem saiu da sala não perde mais vidas
+		}
+		if jogador.vidas > 0 {
+			jogador.vidas--
+		}
+		if jogador.vidas == 0 {
+			atual.eliminados++
+			jogador.eliminado = true
+			jogador.ordemEliminacao = ordem
+			eliminado = jogador
+		}
+	}
+	server.jogadoresMutex.Unlock()
+
+	if eliminado == nil {
+		return
+	}
+	atual.log.Info("jogador eliminado", "jogador", eliminado.ID, "nome", eliminado.Nome, "ordem", eliminado.ordemEliminacao)
+	server.enviar(eliminado, []byte("{\"tipo\":\"eliminado\"}\n"))
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"testing"
+	"triviaMultiplayer/internal/models"
+)
+
+func TestSemAcertoSeparaNomesRepetidos(t *testing.T) {
+	primeira, segunda := &Jogador{ID: 1, Nome: "ana"}, &Jogador{ID: 2, Nome: "ana"}
+	respostas := []models.Resposta{
+		{Jogador: "ana", JogadorID: 1, Correta: true},
+		{Jogador: "ana", JogadorID: 2, Correta: false},
+	}
+
+	perderam := semAcerto(respostas, []*Jogador{primeira, segunda})
+	if len(perderam) != 1 || perderam[0] != segunda {
+		t.Fatalf("semAcerto = %v, esperava só a segunda ana", perderam)
+	}
+}
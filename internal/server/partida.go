@@ -0,0 +1,570 @@
+// Partida: regras configuráveis e o laço de uma partida, controlado pelo console ou pela API de administração
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"triviaMultiplayer/internal/idioma"
+	"triviaMultiplayer/internal/models"
+	"triviaMultiplayer/internal/perguntas"
+)
+
+var (
+	// ErrPartidaEmAndamento indica que já existe uma partida sendo jogada
+	ErrPartidaEmAndamento = errors.New("já existe uma partida em andamento")
+	// ErrSemPartida indica que não há partida para pausar, retomar ou abortar
+	ErrSemPartida = errors.New("nenhuma partida em andamento")
+	// ErrSemJogadores indica que não há jogadores conectados para começar a partida
+	ErrSemJogadores = errors.New("nenhum jogador conectado")
+	// ErrServidorEncerrando indica que o servidor está sendo encerrado e não começa novas partidas
+	ErrServidorEncerrando = errors.New("o servidor está sendo encerrado")
+)
+
+// Config são as regras usadas nas próximas partidas
+type Config struct {
+	NumPerguntas         int      `json:"num_perguntas"`
+	TempoResposta        int      `json:"tempo_resposta"` // segundos
+	EmbaralharPorJogador bool     `json:"embaralhar_por_jogador"`
+	Pacotes              []string `json:"pacotes"`        // vazio usa todos os pacotes
+	EntradaTardia        string   `json:"entrada_tardia"` // fila, zero ou menor
+	CompensarLatencia    bool     `json:"compensar_latencia"`
+	EmpateMs             int      `json:"empate_ms"`              // respostas a até esse tempo empatam
+	TempoRevelacao       int      `json:"tempo_revelacao"`        // segundos mostrando a resposta certa
+	TempoPlacar          int      `json:"tempo_placar"`           // segundos mostrando o placar parcial
+	Modo                 string   `json:"modo"`                   // classico, sobrevivencia, eliminacao, morte_subita, contra_relogio, rei_da_colina ou campainha
+	DuracaoContraRelogio int      `json:"duracao_contra_relogio"` // segundos de jogo no modo contra_relogio
+	Vidas                int      `json:"vidas"`                  // vidas de cada jogador no modo eliminacao
+	RodadaFinal          bool     `json:"rodada_final"`           // termina com uma pergunta em que cada um aposta parte dos pontos
+	Ajudas               int      `json:"ajudas"`                 // quantas vezes cada ajuda pode ser usada por partida; 0 desliga
+}
+
+// ConfigPadrao retorna as regras originais do jogo: 5 perguntas de 10 segundos
+func ConfigPadrao() Config {
+	return Config{NumPerguntas: 5, TempoResposta: 10, EntradaTardia: EntradaFila, TempoRevelacao: 5, TempoPlacar: 5,
+		Modo: ModoClassico, DuracaoContraRelogio: 120, Vidas: 3, Ajudas: 1}
+}
+
+// Validar verifica se as regras podem ser usadas numa partida
+func (config Config) Validar() error {
+	if config.NumPerguntas < 1 || config.NumPerguntas > 100 {
+		return fmt.Errorf("num_perguntas deve estar entre 1 e 100")
+	}
+	if config.TempoResposta < 3 || config.TempoResposta > 120 {
+		return fmt.Errorf("tempo_resposta deve estar entre 3 e 120 segundos")
+	}
+	switch config.EntradaTardia {
+	case EntradaFila, EntradaZero, EntradaMenor:
+	default:
+		return fmt.Errorf("entrada_tardia deve ser fila, zero ou menor")
+	}
+	if config.EmpateMs < 0 || config.EmpateMs > 1000 {
+		return fmt.Errorf("empate_ms deve estar entre 0 e 1000")
+	}
+	if config.TempoRevelacao < 1 || config.TempoRevelacao > 60 {
+		return fmt.Errorf("tempo_revelacao deve estar entre 1 e 60 segundos")
+	}
+	if config.TempoPlacar < 1 || config.TempoPlacar > 60 {
+		return fmt.Errorf("tempo_placar deve estar entre 1 e 60 segundos")
+	}
+	if _, err := NovoModo(config.Modo); err != nil {
+		return err
+	}
+	if config.DuracaoContraRelogio < 30 || config.DuracaoContraRelogio > 3600 {
+		return fmt.Errorf("duracao_contra_relogio deve estar entre 30 e 3600 segundos")
+	}
+	if config.Vidas < 1 || config.Vidas > 10 {
+		return fmt.Errorf("vidas deve estar entre 1 e 10")
+	}
+	if config.Ajudas < 0 || config.Ajudas > 5 {
+		return fmt.Errorf("ajudas deve estar entre 0 e 5")
+	}
+	return nil
+}
+
+// Desempate retorna as regras de ordem entre quem acertou
+func (config Config) Desempate() Desempate {
+	return Desempate{CompensarLatencia: config.CompensarLatencia, Empate: time.Duration(config.EmpateMs) * time.Millisecond}
+}
+
+// EstadoPartida resume a partida atual e o placar ao vivo
+type EstadoPartida struct {
+	EmAndamento bool               `json:"em_andamento"`
+	Pausada     bool               `json:"pausada"`
+	Pergunta    int                `json:"pergunta"` // número da pergunta atual, a partir de 1
+	Total       int                `json:"total"`
+	Placar      []models.Pontuacao `json:"placar"`
+}
+
+// partida é o controle da partida em andamento
+type partida struct {
+	id         int
+	log        *slog.Logger
+	cancelar   context.CancelCauseFunc
+	fim        chan struct{} // fechado quando a partida termina
+	pausar     chan struct{} // fechado ao pausar; recriado ao retomar
+	retomar    chan struct{} // não nulo enquanto pausada; fechado ao retomar
+	pular      chan struct{} // pedido do anfitrião para encerrar a etapa atual
+	pergunta   int
+	total      int
+	vidas      int              // vidas iniciais nos modos com vidas; quem chega no meio espera a próxima
+	eliminados int              // quantos já foram eliminados, para a ordem de eliminação
+	inicio     time.Time        // quando a primeira pergunta saiu
+	pausado    time.Duration    // tempo pausado desde o início
+	final      *models.Pergunta // pergunta guardada para a rodada final com apostas
+}
+
+// UsarBanco define o banco de onde as perguntas das partidas são sorteadas
+func (server *ServerJogo) UsarBanco(banco *perguntas.Banco) {
+	server.banco = banco
+}
+
+// Banco retorna o banco de perguntas do servidor
+func (server *ServerJogo) Banco() *perguntas.Banco {
+	return server.banco
+}
+
+// Config retorna uma cópia das regras atuais
+func (server *ServerJogo) Config() Config {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	config := server.config
+	config.Pacotes = append([]string(nil), config.Pacotes...)
+	return config
+}
+
+// DefinirConfig troca as regras das próximas partidas
+func (server *ServerJogo) DefinirConfig(config Config) error {
+	// Configurações de antes desses campos existirem ficam com os valores padrão
+	padrao := ConfigPadrao()
+	if config.EntradaTardia == "" {
+		config.EntradaTardia = padrao.EntradaTardia
+	}
+	if config.TempoRevelacao == 0 {
+		config.TempoRevelacao = padrao.TempoRevelacao
+	}
+	if config.TempoPlacar == 0 {
+		config.TempoPlacar = padrao.TempoPlacar
+	}
+	if config.Modo == "" {
+		config.Modo = padrao.Modo
+	}
+	if config.DuracaoContraRelogio == 0 {
+		config.DuracaoContraRelogio = padrao.DuracaoContraRelogio
+	}
+	if config.Vidas == 0 {
+		config.Vidas = padrao.Vidas
+	}
+	if err := config.Validar(); err != nil {
+		return err
+	}
+	if server.banco != nil {
+		for _, nome := range config.Pacotes {
+			if _, ok := server.banco.Pacote(nome); !ok {
+				return fmt.Errorf("pacote %q não encontrado", nome)
+			}
+		}
+	}
+
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+	server.config = config
+	return nil
+}
+
+// IniciarPartida sorteia as perguntas e começa uma partida com os jogadores conectados.
+// Sem pacotes, usa os pacotes da configuração (ou todos). A partida corre numa goroutine.
+func (server *ServerJogo) IniciarPartida(pacotes []string) error {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida != nil {
+		return ErrPartidaEmAndamento
+	}
+	if server.estaEncerrando() {
+		return ErrServidorEncerrando
+	}
+	if server.banco == nil {
+		return fmt.Errorf("nenhum banco de perguntas definido")
+	}
+	jogadores := server.RetornarJogadores()
+	if len(jogadores) == 0 {
+		return ErrSemJogadores
+	}
+
+	config := server.config
+	if len(pacotes) == 0 {
+		pacotes = config.Pacotes
+	}
+	var nomes []string
+	for _, jogador := range jogadores {
+		nomes = append(nomes, jogador.Nome)
+	}
+	modo, err := NovoModo(config.Modo)
+	if err != nil {
+		return err
+	}
+	limite := modo.Perguntas(config)
+	if config.RodadaFinal && limite > 0 {
+		limite++ // mais uma para a rodada final
+	}
+	perguntasPartida, err := server.banco.Sortear(pacotes, limite, nomes) // evita as perguntas já vistas
+	if err != nil {
+		return err
+	}
+	var final *models.Pergunta
+	if config.RodadaFinal && len(perguntasPartida) > 1 {
+		final = &perguntasPartida[len(perguntasPartida)-1]
+		perguntasPartida = perguntasPartida[:len(perguntasPartida)-1]
+	}
+	modo.Preparar(perguntasPartida, nomes, config)
+
+	ctx, cancelar := context.WithCancelCause(context.Background())
+	server.proximaPartida++
+	atual := &partida{
+		id:       server.proximaPartida,
+		log:      server.log.With("partida", server.proximaPartida),
+		cancelar: cancelar,
+		fim:      make(chan struct{}),
+		pausar:   make(chan struct{}),
+		pular:    make(chan struct{}, 1),
+		total:    len(perguntasPartida),
+		final:    final,
+	}
+	if final != nil {
+		atual.total++
+	}
+	server.partida = atual
+	atual.log.Info("partida iniciada", "modo", config.Modo, "jogadores", len(jogadores), "perguntas", len(perguntasPartida), "pacotes", pacotes)
+
+	fmt.Println()
+	fmt.Println(idioma.T("JogoVaiComecar", idioma.Dados{"Quantidade": len(jogadores)}))
+	server.TransmitirMsg([]byte("{\"tipo\":\"inicio_jogo\"}\n"))
+
+	go server.executarPartida(ctx, atual, modo, config)
+	return nil
+}
+
+// AguardarPartida bloqueia até a partida em andamento terminar, ou até o contexto acabar
+func (server *ServerJogo) AguardarPartida(ctx context.Context) error {
+	server.partidaMutex.Lock()
+	atual := server.partida
+	server.partidaMutex.Unlock()
+
+	if atual == nil {
+		return nil
+	}
+	select {
+	case <-atual.fim:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pausar congela a partida na etapa em que está: os relógios do servidor e os cronômetros
+// dos jogadores param até a retomada
+func (server *ServerJogo) Pausar() error {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida == nil {
+		return ErrSemPartida
+	}
+	if server.partida.retomar == nil {
+		server.partida.retomar = make(chan struct{})
+		close(server.partida.pausar)
+		server.partida.log.Info("partida pausada")
+		fmt.Println(idioma.T("PartidaPausada"))
+	}
+	return nil
+}
+
+// Retomar continua uma partida pausada
+func (server *ServerJogo) Retomar() error {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida == nil {
+		return ErrSemPartida
+	}
+	if server.partida.retomar != nil {
+		close(server.partida.retomar)
+		server.partida.retomar = nil
+		server.partida.pausar = make(chan struct{})
+		server.partida.log.Info("partida retomada")
+		fmt.Println(idioma.T("PartidaRetomada"))
+	}
+	return nil
+}
+
+// Pular encerra na hora a etapa atual da partida: a contagem, a pergunta aberta, a revelação da
+// resposta ou o placar parcial
+func (server *ServerJogo) Pular() error {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida == nil {
+		return ErrSemPartida
+	}
+	select {
+	case server.partida.pular <- struct{}{}:
+		server.partida.log.Info("etapa pulada")
+		fmt.Println(idioma.T("EtapaPulada"))
+	default: // já há um pulo pendente
+	}
+	return nil
+}
+
+// Abortar encerra a partida em andamento, enviando o placar atual como final
+func (server *ServerJogo) Abortar() error {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida == nil {
+		return ErrSemPartida
+	}
+	server.partida.cancelar(nil)
+	return nil
+}
+
+// Estado retorna a situação da partida e o placar atual
+func (server *ServerJogo) Estado() EstadoPartida {
+	server.partidaMutex.Lock()
+	estado := EstadoPartida{}
+	if server.partida != nil {
+		estado.EmAndamento = true
+		estado.Pausada = server.partida.retomar != nil
+		estado.Pergunta = server.partida.pergunta
+		estado.Total = server.partida.total
+	}
+	server.partidaMutex.Unlock()
+
+	estado.Placar = server.Placar()
+	return estado
+}
+
+// emPartida informa se há uma partida em andamento
+func (server *ServerJogo) emPartida() bool {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+	return server.partida != nil
+}
+
+// Placar retorna a pontuação atual dos jogadores conectados, sem os que esperam a próxima partida
+func (server *ServerJogo) Placar() []models.Pontuacao {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	pontuacoes := make([]models.Pontuacao, 0, len(server.jogadores))
+	for _, jogador := range server.jogadores {
+		if jogador.aguardando {
+			continue
+		}
+		pontuacoes = append(pontuacoes, models.Pontuacao{
+			Jogador:   jogador.Nome,
+			Pontos:    jogador.Pontuacao,
+			Vidas:     jogador.vidas,
+			Eliminado: jogador.ordemEliminacao,
+		})
+	}
+	return pontuacoes
+}
+
+// executarPartida segue as regras do modo: envia as perguntas, coleta as respostas e distribui
+// os pontos até o modo dizer que a partida terminou
+func (server *ServerJogo) executarPartida(ctx context.Context, atual *partida, modo ModoJogo, config Config) {
+	defer func() {
+		atual.cancelar(nil)
+		server.partidaMutex.Lock()
+		server.partida = nil
+		server.partidaMutex.Unlock()
+		server.liberarFila()
+		// As perguntas vistas vão para o disco uma vez por partida, fora do caminho das perguntas
+		if err := server.banco.SalvarHistorico(); err != nil {
+			atual.log.Warn("erro ao salvar o histórico de perguntas", "erro", err)
+		}
+		close(atual.fim)
+	}()
+
+	tempoRevelacao, tempoPlacar := modo.Intervalos()
+	if vidas := modo.Vidas(); vidas > 0 {
+		server.darVidas(atual, vidas)
+	}
+	server.darAjudas(config.Ajudas)
+	if err := server.contagemRegressiva(ctx, atual, 3); err != nil {
+		server.encerrarAbortada(ctx, atual)
+		return
+	}
+	server.partidaMutex.Lock()
+	atual.inicio = time.Now()
+	atual.pausado = 0
+	server.partidaMutex.Unlock()
+
+	for i := 0; !modo.Terminou(server.tempoDeJogo(atual), len(server.jogadoresEmJogo())); i++ {
+		emJogo := server.emJogo()
+		pergunta, tempoResposta := modo.ProximaPergunta(server.tempoDeJogo(atual))
+		server.partidaMutex.Lock()
+		atual.pergunta = i + 1
+		server.partidaMutex.Unlock()
+
+		pergunta.Tempo = segundosRestantes(tempoResposta)
+		respostas := modo.Coletar(ctx, server, atual, pergunta, tempoResposta)
+		atual.log.Info("respostas coletadas", "pergunta", pergunta.ID, "respostas", len(respostas))
+		if ctx.Err() != nil {
+			// Abortada no meio da pergunta: ela não vale pontos
+			server.encerrarAbortada(ctx, atual)
+			return
+		}
+		server.encerrarPergunta(pergunta, len(respostas), segundosRestantes(tempoRevelacao))
+
+		rodada := modo.Pontuar(respostas, emJogo)
+		rodada.Pontos = dobrarPontos(rodada.Pontos, respostas)
+		for _, ponto := range rodada.Pontos {
+			server.AtualizarPontos(ponto.Jogador, ponto.Pontos)
+		}
+		ordem := atual.eliminados + 1
+		for _, jogador := range rodada.PerderamVida {
+			server.tirarVida(atual, jogador, ordem)
+		}
+
+		if _, err := server.esperarEtapa(ctx, atual, tempoRevelacao); err != nil { // Pausa para a tela de Resultado da resposta
+			server.encerrarAbortada(ctx, atual)
+			return
+		}
+
+		// O placar parcial só aparece se a partida continua
+		if tempoPlacar > 0 && !modo.Terminou(server.tempoDeJogo(atual), len(server.jogadoresEmJogo())) {
+			server.enviarPlacarTempo("placar", segundosRestantes(tempoPlacar))
+			if _, err := server.esperarEtapa(ctx, atual, tempoPlacar); err != nil {
+				server.encerrarAbortada(ctx, atual)
+				return
+			}
+		}
+	}
+
+	if atual.final != nil && len(server.jogadoresEmJogo()) > 0 {
+		if err := server.rodadaFinal(ctx, atual, *atual.final, config, tempoRevelacao); err != nil {
+			server.encerrarAbortada(ctx, atual)
+			return
+		}
+	}
+
+	server.metricas.partidas.WithLabelValues("completa").Inc()
+	atual.log.Info("partida encerrada", "resultado", "completa")
+	fmt.Println(idioma.T("FimDeJogo"))
+	// Envia o placar final com o tipo "fim_de_jogo".
+	server.enviarPlacar("fim_de_jogo")
+
+	time.Sleep(1 * time.Second)
+}
+
+// tempoDeJogo é quanto a partida já correu desde a primeira pergunta, sem contar as pausas
+func (server *ServerJogo) tempoDeJogo(atual *partida) time.Duration {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+	return time.Since(atual.inicio) - atual.pausado
+}
+
+// encerrarPergunta avisa a cada jogador que a pergunta fechou, com a resposta certa na ordem dele,
+// quantos responderam e quanto tempo a revelação fica na tela
+func (server *ServerJogo) encerrarPergunta(pergunta models.Pergunta, respostas int, tempo int) {
+	server.jogadoresMutex.Lock()
+	defer server.jogadoresMutex.Unlock()
+
+	for _, jogador := range server.jogadores {
+		if jogador.aguardando {
+			continue
+		}
+		resultado := montarResultado(pergunta, jogador.Idioma, jogador.ordem, Avaliacao{})
+		encerrada, _ := json.Marshal(models.PerguntaEncerrada{
+			Tipo:            "pergunta_encerrada",
+			ID:              pergunta.ID,
+			RespostaCorreta: resultado.RespostaCorreta,
+			TextoCorreto:    resultado.TextoCorreto,
+			Respostas:       respostas,
+			Tempo:           tempo,
+		})
+		server.enviar(jogador, append(encerrada, '\n'))
+	}
+}
+
+// encerrarAbortada avisa o console e manda o placar atual como final.
+// Se o motivo é o encerramento do servidor, o placar vai na mensagem servidor_encerrando.
+func (server *ServerJogo) encerrarAbortada(ctx context.Context, atual *partida) {
+	server.metricas.partidas.WithLabelValues("abortada").Inc()
+	atual.log.Info("partida encerrada", "resultado", "abortada", "motivo", context.Cause(ctx))
+	if errors.Is(context.Cause(ctx), ErrServidorEncerrando) {
+		return
+	}
+	fmt.Println(idioma.T("PartidaAbortada"))
+	server.enviarPlacar("fim_de_jogo")
+}
+
+// comPartida acrescenta o ID da partida em andamento ao logger, se houver partida
+func (server *ServerJogo) comPartida(log *slog.Logger) *slog.Logger {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+
+	if server.partida != nil {
+		return log.With("partida", server.partida.id)
+	}
+	return log
+}
+
+// esperarEtapa dura o tempo pedido, sem contar as pausas, até o anfitrião pular a etapa
+// (pulada) ou a partida ser abortada
+func (server *ServerJogo) esperarEtapa(ctx context.Context, atual *partida, tempo time.Duration) (pulada bool, err error) {
+	relogio := server.novoRelogio(atual, tempo)
+	defer relogio.parar()
+
+	for {
+		select {
+		case <-relogio.timer.C:
+			return false, nil
+		case <-atual.pular:
+			return true, nil
+		case <-relogio.pausar:
+			if err := relogio.congelar(ctx); err != nil {
+				return false, err
+			}
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// Faz contagem regressiva inicial; pular a etapa vai direto para o "já"
+func (server *ServerJogo) contagemRegressiva(ctx context.Context, atual *partida, valor int) error {
+	for i := valor; i > 0; i-- {
+		server.transmitirPartida([]byte(fmt.Sprintf("{\"tipo\":\"contagem_regressiva\",\"valor\":%d}\n", i)))
+		fmt.Println(idioma.T("ComecandoEm", idioma.Dados{"Valor": i}))
+		pulada, err := server.esperarEtapa(ctx, atual, 1*time.Second)
+		if err != nil {
+			return err
+		}
+		if pulada {
+			break
+		}
+	}
+	server.transmitirPartida([]byte("{\"tipo\":\"contagem_regressiva\",\"valor\":0}\n"))
+	return nil
+}
+
+// Envia placar
+func (server *ServerJogo) enviarPlacar(tipoMsg string) {
+	server.enviarPlacarTempo(tipoMsg, 0)
+}
+
+// enviarPlacarTempo envia o placar com os segundos até a próxima pergunta
+func (server *ServerJogo) enviarPlacarTempo(tipoMsg string, tempo int) {
+	// Usa o tipo de mensagem que foi passado como argumento.
+	placar := models.Placar{Tipo: tipoMsg, Pontuacoes: server.Placar(), Tempo: tempo}
+	placarBytes, _ := json.Marshal(placar)
+	server.transmitirPartida(append(placarBytes, '\n'))
+}
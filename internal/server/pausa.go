@@ -0,0 +1,107 @@
+// Pausa da partida: o relógio de cada etapa para enquanto a partida está pausada, e os jogadores
+// recebem pausa e retomada com o tempo que falta, para congelar os cronômetros das telas
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// relogio conta o tempo de uma etapa da partida (contagem, pergunta, revelação ou placar)
+type relogio struct {
+	server *ServerJogo
+	atual  *partida
+	fim    time.Time     // quando a etapa acaba, se não houver mais pausas
+	timer  *time.Timer   // dispara no fim da etapa
+	pausar chan struct{} // fechado quando a partida é pausada
+}
+
+// novoRelogio começa a contar o tempo de uma etapa
+func (server *ServerJogo) novoRelogio(atual *partida, tempo time.Duration) *relogio {
+	return &relogio{
+		server: server,
+		atual:  atual,
+		fim:    time.Now().Add(tempo),
+		timer:  time.NewTimer(tempo),
+		pausar: server.sinalPausa(atual),
+	}
+}
+
+// restante é o tempo que falta na etapa
+func (r *relogio) restante() time.Duration {
+	if restante := time.Until(r.fim); restante > 0 {
+		return restante
+	}
+	return 0
+}
+
+// congelar para o relógio até a partida ser retomada, avisando os jogadores nas duas pontas.
+// Se a partida for abortada durante a pausa, retorna o erro do contexto.
+func (r *relogio) congelar(ctx context.Context) error {
+	r.timer.Stop()
+	restante := r.restante()
+	r.server.avisarPausa("pausa", restante)
+
+	pausa := time.Now()
+	if err := r.server.esperarRetomada(ctx, r.atual); err != nil {
+		return err
+	}
+	r.server.partidaMutex.Lock()
+	r.atual.pausado += time.Since(pausa)
+	r.server.partidaMutex.Unlock()
+	r.fim = time.Now().Add(restante)
+	r.timer = time.NewTimer(restante)
+	r.pausar = r.server.sinalPausa(r.atual)
+	r.server.avisarPausa("retomada", restante)
+	return nil
+}
+
+// prorrogar recomeça o relógio com mais tempo, depois de ele ter disparado
+func (r *relogio) prorrogar(tempo time.Duration) {
+	r.fim = time.Now().Add(tempo)
+	r.timer = time.NewTimer(tempo)
+}
+
+// parar libera o timer do relógio
+func (r *relogio) parar() {
+	r.timer.Stop()
+}
+
+// sinalPausa retorna o canal fechado quando a partida for pausada
+func (server *ServerJogo) sinalPausa(atual *partida) chan struct{} {
+	server.partidaMutex.Lock()
+	defer server.partidaMutex.Unlock()
+	return atual.pausar
+}
+
+// esperarRetomada bloqueia enquanto a partida estiver pausada
+func (server *ServerJogo) esperarRetomada(ctx context.Context, atual *partida) error {
+	server.partidaMutex.Lock()
+	retomar := atual.retomar
+	server.partidaMutex.Unlock()
+
+	if retomar == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-retomar:
+		return ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// avisarPausa manda pausa ou retomada para quem está jogando, com os segundos que faltam na etapa
+func (server *ServerJogo) avisarPausa(tipo string, restante time.Duration) {
+	msg, _ := json.Marshal(models.Pausa{Tipo: tipo, Restante: segundosRestantes(restante)})
+	server.transmitirPartida(append(msg, '\n'))
+}
+
+// segundosRestantes arredonda para cima, como os cronômetros dos clientes mostram
+func segundosRestantes(restante time.Duration) int {
+	return int(math.Ceil(restante.Seconds()))
+}
@@ -0,0 +1,93 @@
+// Certificados TLS do servidor: carregados do disco ou gerados (autoassinados) para uso na rede local
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+	"triviaMultiplayer/internal/certificado"
+)
+
+// Validade dos certificados autoassinados gerados pelo servidor
+const validadeCertificado = 365 * 24 * time.Hour
+
+// CarregarCertificado lê o par certificado/chave. Se os arquivos não existirem e
+// gerar for verdadeiro, cria um certificado autoassinado e o salva nesses caminhos.
+func CarregarCertificado(arquivoCert, arquivoChave string, gerar bool) (tls.Certificate, error) {
+	_, errCert := os.Stat(arquivoCert)
+	_, errChave := os.Stat(arquivoChave)
+	if gerar && errors.Is(errCert, os.ErrNotExist) && errors.Is(errChave, os.ErrNotExist) {
+		if err := gerarCertificado(arquivoCert, arquivoChave); err != nil {
+			return tls.Certificate{}, err
+		}
+	}
+
+	certificado, err := tls.LoadX509KeyPair(arquivoCert, arquivoChave)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("erro ao carregar o certificado TLS: %w", err)
+	}
+	return certificado, nil
+}
+
+// gerarCertificado cria um certificado autoassinado válido para localhost e o IP local
+func gerarCertificado(arquivoCert, arquivoChave string) error {
+	chave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar a chave TLS: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("erro ao gerar o certificado TLS: %w", err)
+	}
+
+	modelo := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Trivia Multiplayer"}, CommonName: "trivia-multiplayer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validadeCertificado),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if ip := net.ParseIP(ObterIPlocal()); ip != nil {
+		modelo.IPAddresses = append(modelo.IPAddresses, ip)
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &modelo, &modelo, &chave.PublicKey, chave)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar o certificado TLS: %w", err)
+	}
+	chaveDER, err := x509.MarshalECPrivateKey(chave)
+	if err != nil {
+		return fmt.Errorf("erro ao codificar a chave TLS: %w", err)
+	}
+
+	if err := os.WriteFile(arquivoCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return fmt.Errorf("erro ao salvar o certificado TLS: %w", err)
+	}
+	if err := os.WriteFile(arquivoChave, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: chaveDER}), 0600); err != nil {
+		return fmt.Errorf("erro ao salvar a chave TLS: %w", err)
+	}
+	return nil
+}
+
+// ImpressaoDigital retorna o SHA-256 do certificado no formato AB:CD:..., o mesmo mostrado pelo cliente
+func ImpressaoDigital(par tls.Certificate) string {
+	if len(par.Certificate) == 0 {
+		return ""
+	}
+	return certificado.Impressao(par.Certificate[0])
+}
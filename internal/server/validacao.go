@@ -0,0 +1,175 @@
+// Validação das mensagens dos jogadores: só vale uma resposta por pergunta, para a pergunta aberta
+// e com uma alternativa que o jogador recebeu. O que não passa é descartado e vai para a auditoria.
+
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// Valores padrão da validação
+const (
+	ReacaoMinimaPadrao        = 100 * time.Millisecond
+	MensagensPorSegundoPadrao = 10
+)
+
+// Motivos de rejeição registrados na auditoria e na métrica trivia_mensagens_rejeitadas_total
+const (
+	motivoJSONInvalido     = "json_invalido"
+	motivoTipoDesconhecido = "tipo_desconhecido"
+	motivoSemPergunta      = "sem_pergunta"
+	motivoPartidaPausada   = "partida_pausada"
+	motivoForaDaPartida    = "fora_da_partida"
+	motivoPerguntaErrada   = "pergunta_errada"
+	motivoRespostaRepetida = "resposta_repetida"
+	motivoForaDaVez        = "fora_da_vez"
+	motivoSemCampainha     = "sem_campainha"
+	motivoToqueRepetido    = "toque_repetido"
+	motivoSemAposta        = "sem_aposta"
+	motivoApostaInvalida   = "aposta_invalida"
+	motivoApostaRepetida   = "aposta_repetida"
+	motivoAjudaInvalida    = "ajuda_invalida"
+	motivoAjudaRepetida    = "ajuda_repetida"
+	motivoOpcaoInvalida    = "opcao_invalida"
+	motivoOpcaoRemovida    = "opcao_removida"
+	motivoExcessoMensagens = "excesso_mensagens"
+)
+
+// Bytes da mensagem rejeitada guardados na auditoria
+const tamanhoMaximoAuditoria = 200
+
+// UsarAuditoria define onde ficam os registros das mensagens rejeitadas e das respostas suspeitas.
+// Sem ele, vão para o registro normal do servidor.
+func (server *ServerJogo) UsarAuditoria(logger *slog.Logger) {
+	server.auditoria = logger
+}
+
+// ConfigurarValidacao define o tempo de reação abaixo do qual uma resposta é marcada como suspeita
+// e quantas mensagens por segundo cada jogador pode mandar
+func (server *ServerJogo) ConfigurarValidacao(reacaoMinima time.Duration, mensagensPorSegundo int) {
+	server.reacaoMinima = reacaoMinima
+	server.mensagensPorSegundo = mensagensPorSegundo
+}
+
+// receberResposta valida a resposta assim que o leitor a recebe e, se for válida, passa para a coleta
+// com o momento da leitura e o tempo de ida e volta do jogador
+func (server *ServerJogo) receberResposta(jogador *Jogador, linha []byte, recebida time.Time) {
+	var resp models.Resposta
+	if err := json.Unmarshal(linha, &resp); err != nil {
+		server.rejeitar(jogador, motivoJSONInvalido, linha)
+		return
+	}
+
+	server.jogadoresMutex.Lock()
+	atual := server.coleta
+	motivo := ""
+	var reacao time.Duration
+	switch {
+	case atual == nil:
+		motivo = motivoSemPergunta
+	case atual.esgotou && !atual.usouAjuda(jogador, AjudaMaisTempo):
+		motivo = motivoSemPergunta // só quem pediu mais tempo responde depois do fim
+	case jogador.aguardando, jogador.eliminado:
+		motivo = motivoForaDaPartida
+	case atual.pausada:
+		motivo = motivoPartidaPausada
+	case resp.ID != atual.pergunta.ID:
+		motivo = motivoPerguntaErrada
+	case atual.campainha && (atual.vez != jogador.ID || jogador.tocou != atual):
+		motivo = motivoForaDaVez
+	case jogador.respondida == atual:
+		motivo = motivoRespostaRepetida
+	default:
+		avaliacao := AvaliarResposta(atual.pergunta, jogador.Idioma, jogador.ordem, resp.Opcao)
+		if avaliacao.Alternativa < 0 {
+			motivo = motivoOpcaoInvalida
+			break
+		}
+		if atual.removida(jogador, avaliacao.Alternativa) {
+			motivo = motivoOpcaoRemovida // o meio_a_meio já tirou essa alternativa da tela
+			break
+		}
+		resp.Alternativa = avaliacao.Alternativa
+		resp.Correta = avaliacao.Correta
+		resp.Tempo = recebida
+		resp.Dobro = atual.usouAjuda(jogador, AjudaDobro)
+		if jogador.rtt > 0 {
+			resp.RTT = jogador.rtt
+		}
+		jogador.respondida = atual
+		// Sem a marca da escritora, a resposta chegou antes de a pergunta sair da fila: reação zero
+		if !jogador.perguntaEm.IsZero() {
+			reacao = recebida.Sub(jogador.perguntaEm)
+		}
+	}
+	server.jogadoresMutex.Unlock()
+
+	if motivo != "" {
+		server.rejeitar(jogador, motivo, linha)
+		return
+	}
+	if reacao < server.reacaoMinima {
+		// Rápido demais para um humano: a resposta vale, mas fica registrada
+		server.metricas.respostasSuspeitas.Inc()
+		server.comPartida(server.registroAuditoria()).Warn("resposta suspeita",
+			"jogador", jogador.ID, "nome", jogador.Nome, "ip", jogador.IP,
+			"pergunta", resp.ID, "reacao", reacao.String(), "minimo", server.reacaoMinima.String())
+	}
+
+	// Cabe sempre: só uma resposta por pergunta passa da validação
+	select {
+	case jogador.respostas <- resp:
+	default:
+		server.rejeitar(jogador, motivoRespostaRepetida, linha)
+	}
+}
+
+// rejeitar descarta a mensagem, contando na métrica e registrando na auditoria
+func (server *ServerJogo) rejeitar(jogador *Jogador, motivo string, linha []byte) {
+	server.metricas.mensagensRejeitadas.WithLabelValues(motivo).Inc()
+
+	trecho := strings.TrimSpace(string(linha))
+	if len(trecho) > tamanhoMaximoAuditoria {
+		trecho = trecho[:tamanhoMaximoAuditoria]
+	}
+	server.comPartida(server.registroAuditoria()).Warn("mensagem rejeitada",
+		"jogador", jogador.ID, "nome", jogador.Nome, "ip", jogador.IP, "motivo", motivo, "mensagem", trecho)
+}
+
+// registroAuditoria retorna o logger da auditoria, ou o do servidor se não houver um próprio
+func (server *ServerJogo) registroAuditoria() *slog.Logger {
+	if server.auditoria != nil {
+		return server.auditoria
+	}
+	return server.log.With("auditoria", true)
+}
+
+// limiteMensagens conta as mensagens de um jogador numa janela de um segundo
+type limiteMensagens struct {
+	inicio   time.Time
+	contagem int
+}
+
+// permitir informa se a mensagem cabe no limite por segundo. Só a primeira mensagem além do
+// limite em cada janela vai para a auditoria, para um cliente enlouquecido não encher o registro.
+func (server *ServerJogo) permitir(jogador *Jogador, limite *limiteMensagens, linha []byte) bool {
+	agora := time.Now()
+	if agora.Sub(limite.inicio) >= time.Second {
+		limite.inicio = agora
+		limite.contagem = 0
+	}
+	limite.contagem++
+	if limite.contagem <= server.mensagensPorSegundo {
+		return true
+	}
+	if limite.contagem == server.mensagensPorSegundo+1 {
+		server.rejeitar(jogador, motivoExcessoMensagens, linha)
+	} else {
+		server.metricas.mensagensRejeitadas.WithLabelValues(motivoExcessoMensagens).Inc()
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+	"triviaMultiplayer/internal/models"
+)
+
+// auditoriaTeste guarda o que vai para a auditoria: o motivo de cada mensagem rejeitada e as
+// respostas suspeitas
+type auditoriaTeste struct {
+	mutex     *sync.Mutex
+	motivos   []string
+	suspeitas int
+}
+
+// auditar troca a auditoria do servidor por uma que os testes podem consultar
+func auditar(server *ServerJogo) *auditoriaTeste {
+	auditoria := &auditoriaTeste{mutex: &sync.Mutex{}}
+	server.UsarAuditoria(slog.New(slog.NewJSONHandler(auditoria, nil)))
+	return auditoria
+}
+
+func (auditoria *auditoriaTeste) Write(p []byte) (int, error) {
+	var registro struct {
+		Msg    string `json:"msg"`
+		Motivo string `json:"motivo"`
+	}
+	for _, linha := range bytes.Split(bytes.TrimSpace(p), []byte("\n")) {
+		if json.Unmarshal(linha, &registro) != nil {
+			continue
+		}
+		auditoria.mutex.Lock()
+		if registro.Motivo != "" {
+			auditoria.motivos = append(auditoria.motivos, registro.Motivo)
+		}
+		if registro.Msg == "resposta suspeita" {
+			auditoria.suspeitas++
+		}
+		auditoria.mutex.Unlock()
+	}
+	return len(p), nil
+}
+
+// ultimo é o motivo da última rejeição, ou "" se nada foi rejeitado
+func (auditoria *auditoriaTeste) ultimo() string {
+	auditoria.mutex.Lock()
+	defer auditoria.mutex.Unlock()
+	if len(auditoria.motivos) == 0 {
+		return ""
+	}
+	return auditoria.motivos[len(auditoria.motivos)-1]
+}
+
+// rejeicoes conta as mensagens rejeitadas até agora
+func (auditoria *auditoriaTeste) rejeicoes() int {
+	auditoria.mutex.Lock()
+	defer auditoria.mutex.Unlock()
+	return len(auditoria.motivos)
+}
+
+// jogadorTeste é um jogador sem conexão que viu perguntaTeste na ordem original
+func jogadorTeste(id int, nome string) *Jogador {
+	return &Jogador{
+		ID:        id,
+		Nome:      nome,
+		ordem:     []int{0, 1, 2, 3},
+		log:       slog.Default(),
+		respostas: make(chan models.Resposta, 1),
+		rtt:       -1,
+	}
+}
+
+// coletaTeste é uma pergunta aberta para perguntaTeste, sem goroutine de coleta
+func coletaTeste() *coleta {
+	return &coleta{
+		pergunta:  perguntaTeste,
+		limite:    time.Now().Add(10 * time.Second),
+		respostas: make(chan models.Resposta, 4),
+		esgotado:  make(chan struct{}),
+		fim:       make(chan struct{}),
+	}
+}
+
+func TestReceberResposta(t *testing.T) {
+	casos := []struct {
+		nome     string
+		preparar func(server *ServerJogo, jogador *Jogador, atual *coleta)
+		linha    string
+		motivo   string // vazio quando a resposta é aceita
+	}{
+		{"aceita", nil, `{"tipo":"resposta","id":1,"opcao":"C"}`, ""},
+		{"json inválido", nil, `{"tipo":"resposta","id":`, motivoJSONInvalido},
+		{"sem pergunta aberta", func(server *ServerJogo, jogador *Jogador, atual *coleta) { server.coleta = nil },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoSemPergunta},
+		{"id de outra pergunta", nil, `{"tipo":"resposta","id":2,"opcao":"C"}`, motivoPerguntaErrada},
+		{"resposta repetida", func(server *ServerJogo, jogador *Jogador, atual *coleta) { jogador.respondida = atual },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoRespostaRepetida},
+		{"partida pausada", func(server *ServerJogo, jogador *Jogador, atual *coleta) { atual.pausada = true },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoPartidaPausada},
+		{"esperando a próxima partida", func(server *ServerJogo, jogador *Jogador, atual *coleta) { jogador.aguardando = true },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoForaDaPartida},
+		{"eliminado", func(server *ServerJogo, jogador *Jogador, atual *coleta) { jogador.eliminado = true },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoForaDaPartida},
+		{"opção que não existe", nil, `{"tipo":"resposta","id":1,"opcao":"Plutão"}`, motivoOpcaoInvalida},
+		{"tempo esgotado", func(server *ServerJogo, jogador *Jogador, atual *coleta) { atual.esgotou = true },
+			`{"tipo":"resposta","id":1,"opcao":"C"}`, motivoSemPergunta},
+		{"tempo esgotado com mais_tempo", func(server *ServerJogo, jogador *Jogador, atual *coleta) {
+			atual.esgotou = true
+			atual.ajudas = map[*Jogador]map[string]bool{jogador: {AjudaMaisTempo: true}}
+		}, `{"tipo":"resposta","id":1,"opcao":"C"}`, ""},
+		{"alternativa tirada pelo meio_a_meio", func(server *ServerJogo, jogador *Jogador, atual *coleta) {
+			atual.removidas = map[*Jogador][]int{jogador: {0, 3}}
+		}, `{"tipo":"resposta","id":1,"opcao":"D"}`, motivoOpcaoRemovida},
+		{"campainha sem a vez", func(server *ServerJogo, jogador *Jogador, atual *coleta) {
+			atual.campainha = true
+			atual.vez = jogador.ID + 1
+			jogador.tocou = atual
+		}, `{"tipo":"resposta","id":1,"opcao":"C"}`, motivoForaDaVez},
+		{"campainha com a vez de outro com o mesmo nome", func(server *ServerJogo, jogador *Jogador, atual *coleta) {
+			atual.campainha = true
+			atual.vez = 99 // outra "ana"
+			jogador.tocou = atual
+		}, `{"tipo":"resposta","id":1,"opcao":"C"}`, motivoForaDaVez},
+		{"campainha com a vez", func(server *ServerJogo, jogador *Jogador, atual *coleta) {
+			atual.campainha = true
+			atual.vez = jogador.ID
+			jogador.tocou = atual
+		}, `{"tipo":"resposta","id":1,"opcao":"C"}`, ""},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			server := NovoServer(4)
+			auditoria := auditar(server)
+			jogador := jogadorTeste(1, "ana")
+			atual := coletaTeste()
+			server.coleta = atual
+			if caso.preparar != nil {
+				caso.preparar(server, jogador, atual)
+			}
+
+			server.receberResposta(jogador, []byte(caso.linha), time.Now())
+
+			if motivo := auditoria.ultimo(); motivo != caso.motivo {
+				t.Fatalf("motivo = %q, esperava %q", motivo, caso.motivo)
+			}
+			select {
+			case resp := <-jogador.respostas:
+				if caso.motivo != "" {
+					t.Fatalf("resposta %+v passou, esperava rejeição %q", resp, caso.motivo)
+				}
+				if resp.Alternativa != 2 || !resp.Correta {
+					t.Fatalf("resposta = %+v, esperava a alternativa 2 correta", resp)
+				}
+			default:
+				if caso.motivo == "" {
+					t.Fatal("resposta válida não chegou à coleta")
+				}
+			}
+		})
+	}
+}
+
+func TestReceberRespostaMarcaReacaoSuspeita(t *testing.T) {
+	casos := []struct {
+		nome       string
+		perguntaEm time.Duration // há quanto tempo a pergunta saiu; zero é ainda na fila
+		suspeita   bool
+	}{
+		{"reação humana", 2 * time.Second, false},
+		{"rápida demais", 10 * time.Millisecond, true},
+		{"antes de a pergunta sair da fila", 0, true},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			server := NovoServer(4)
+			server.ConfigurarValidacao(500*time.Millisecond, MensagensPorSegundoPadrao)
+			auditoria := auditar(server)
+			jogador := jogadorTeste(1, "ana")
+			server.coleta = coletaTeste()
+			agora := time.Now()
+			if caso.perguntaEm > 0 {
+				jogador.perguntaEm = agora.Add(-caso.perguntaEm)
+			}
+
+			server.receberResposta(jogador, []byte(`{"tipo":"resposta","id":1,"opcao":"C"}`), agora)
+
+			if len(jogador.respostas) != 1 {
+				t.Fatal("a resposta suspeita também vale")
+			}
+			if suspeita := auditoria.suspeitas > 0; suspeita != caso.suspeita {
+				t.Fatalf("suspeita = %v, esperava %v", suspeita, caso.suspeita)
+			}
+		})
+	}
+}
+
+func TestPermitirLimitaMensagensPorSegundo(t *testing.T) {
+	server := NovoServer(4)
+	server.ConfigurarValidacao(ReacaoMinimaPadrao, 3)
+	auditoria := auditar(server)
+	jogador := jogadorTeste(1, "ana")
+	limite := &limiteMensagens{}
+	linha := []byte(`{"tipo":"pong","id":1}`)
+
+	for i := 1; i <= 3; i++ {
+		if !server.permitir(jogador, limite, linha) {
+			t.Fatalf("mensagem %d recusada dentro do limite", i)
+		}
+	}
+	for i := 4; i <= 6; i++ {
+		if server.permitir(jogador, limite, linha) {
+			t.Fatalf("mensagem %d aceita além do limite", i)
+		}
+	}
+	// Só a primeira além do limite vai para a auditoria
+	if auditoria.rejeicoes() != 1 || auditoria.ultimo() != motivoExcessoMensagens {
+		t.Fatalf("auditoria = %v, esperava um %q", auditoria.motivos, motivoExcessoMensagens)
+	}
+
+	// Na janela seguinte a contagem recomeça
+	limite.inicio = limite.inicio.Add(-time.Second)
+	if !server.permitir(jogador, limite, linha) {
+		t.Fatal("mensagem recusada na janela seguinte")
+	}
+}